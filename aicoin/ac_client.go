@@ -3,12 +3,14 @@ package aicoin
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/gotoxu/log/core"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/json-iterator/go/extra"
 )
@@ -16,19 +18,23 @@ import (
 // Client 提供 API的调用客户端
 type Client struct {
 	Config config.Config
+	Logger core.Logger
 }
 
 // Constructor 创建一个新的client
 func (c *Client) Constructor(config *config.Config) {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(nil, config)
 	}
 	extra.RegisterFuzzyDecoders()
 	c.Config = *cfg
+	c.Logger = cfg.ExchangeLogger("aicoin")
 }
 
-func (c *Client) aicoinHTTPReq(method, path string, in map[string]interface{}, out interface{}) error {
+func (c *Client) aicoinHTTPReq(method, path string, in map[string]interface{}, out interface{}) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.Config.ObserveRequest("aicoin", endpoint, status, err, start) }()
 	if in == nil {
 		in = make(map[string]interface{})
 	}
@@ -40,24 +46,31 @@ func (c *Client) aicoinHTTPReq(method, path string, in map[string]interface{}, o
 	path += "?" + utils.MapEncode(in)
 
 	req, err := http.NewRequest(method, path, bytes.NewReader(rbody))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; WOW64) "+
 		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/65.0.3325.181 Safari/537.36")
 
 	req.Header.Set("Referer", "https://www.aicoin.net.cn/")
-	resp, err := c.Config.HTTPClient.Do(req)
+	ctx, cancel := c.Config.RequestContext()
+	defer cancel()
+	resp, err := c.Config.Do("aicoin", req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("aicoin message: %s\n", string(body))
+	config.LogResponse(c.Logger, req, start, body)
 	//extra.RegisterFuzzyDecoders()
 
 	err = jsoniter.Unmarshal(body, out)
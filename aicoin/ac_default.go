@@ -5,6 +5,7 @@ import (
 
 	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/gorilla/websocket"
 )
 
 func defaultConfig() *config.Config {
@@ -17,6 +18,7 @@ func defaultConfig() *config.Config {
 	// u, _ := url.Parse("http://127.0.0.1:8118")
 	// transport.Proxy = http.ProxyURL(u)
 	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithWSSDialer(websocket.DefaultDialer)
 	cfg.WithUseSSL(true)
 
 	return cfg
@@ -18,7 +18,9 @@ func (c *Client) AicoinGetDepth(ex string, req global.TradeSymbol) (global.Depth
 		Asks [][]interface{} `json:"asks"`
 		Bids [][]interface{} `json:"bids"`
 	}{}
-	err := c.aicoinHTTPReq("GET", "https://www.aicoin.net.cn/api/second/depths", in, &r)
+	err := c.Config.RetryDo("aicoin", func() error {
+		return c.aicoinHTTPReq("GET", "https://www.aicoin.net.cn/api/second/depths", in, &r)
+	})
 	if err != nil {
 		return global.Depth{}, err
 	}
@@ -79,7 +81,9 @@ func (c *Client) AicoinGetKline(ex string, req global.KlineReq) ([]global.Kline,
 	r := struct {
 		Data [][]interface{} `json:"data"`
 	}{}
-	err := c.aicoinHTTPReq("GET", "https://www.aicoin.net.cn/api/second/kline", in, &r)
+	err := c.Config.RetryDo("aicoin", func() error {
+		return c.aicoinHTTPReq("GET", "https://www.aicoin.net.cn/api/second/kline", in, &r)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -19,5 +19,6 @@ type Client struct {
 // Constructor 创建一个新的client
 func (c *Client) Constructor(config *config.Config) {
 	c.Client.Constructor(config)
+	c.Logger = c.Config.ExchangeLogger(c.Exchange)
 	c.lastt = make(map[global.TradeSymbol][]global.LateTrade)
 }
@@ -1,10 +1,11 @@
 package baseclass
 
 import (
-	"fmt"
 	"time"
 
+	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 // SubTicker ...
@@ -15,9 +16,14 @@ func (c *Client) SubTicker(sreq global.TradeSymbol) (chan global.Ticker, error)
 		for {
 			t, err := c.Client.AicoinGetTicker(c.Exchange, sreq)
 			if err != nil {
-				fmt.Println(c.Exchange, " ticker error: ", err.Error())
+				c.Logger.With(config.LogSymbol, sreq.Base+sreq.Quote).Logf(core.Warn, "ticker error: %s", err)
 			} else {
-				ch <- t
+				c.Config.ObserveMessage(c.Exchange, "ticker:"+sreq.Base+sreq.Quote)
+				select {
+				case ch <- t:
+				default:
+					c.Config.ObserveDrop(c.Exchange, "ticker:"+sreq.Base+sreq.Quote)
+				}
 			}
 			time.Sleep(10 * time.Second)
 		}
@@ -33,9 +39,14 @@ func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 		for {
 			t, err := c.Client.AicoinGetDepth(c.Exchange, sreq)
 			if err != nil {
-				fmt.Println(c.Exchange, " depth error: ", err.Error())
+				c.Logger.With(config.LogSymbol, sreq.Base+sreq.Quote).Logf(core.Warn, "depth error: %s", err)
 			} else {
-				ch <- t
+				c.Config.ObserveMessage(c.Exchange, "depth:"+sreq.Base+sreq.Quote)
+				select {
+				case ch <- t:
+				default:
+					c.Config.ObserveDrop(c.Exchange, "depth:"+sreq.Base+sreq.Quote)
+				}
 			}
 			time.Sleep(10 * time.Second)
 		}
@@ -51,7 +62,7 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 		for {
 			t, err := c.Client.AicoinGetLateTrade(c.Exchange, sreq)
 			if err != nil {
-				fmt.Println(c.Exchange, " latetrade error: ", err.Error())
+				c.Logger.With(config.LogSymbol, sreq.Base+sreq.Quote).Logf(core.Warn, "latetrade error: %s", err)
 			} else {
 				c.mutex.Lock()
 				lt := c.lastt[sreq]
@@ -69,7 +80,12 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 						}
 					}
 					if !find {
-						ch <- l
+						c.Config.ObserveMessage(c.Exchange, "trade:"+sreq.Base+sreq.Quote)
+						select {
+						case ch <- l:
+						default:
+							c.Config.ObserveDrop(c.Exchange, "trade:"+sreq.Base+sreq.Quote)
+						}
 					}
 				}
 			}
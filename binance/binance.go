@@ -184,7 +184,7 @@ type ExecutedOrder struct {
 	ClientOrderID string
 	Price         float64
 	OrigQty       float64
-	ExecutePrice  float64
+	ExecutePrice  float64 // 累计成交金额(cummulativeQuoteQty)，不是成交均价
 	ExecutedQty   float64
 	Status        OrderStatus
 	TimeInForce   TimeInForce
@@ -221,10 +221,14 @@ type OpenOrdersRequest struct {
 }
 
 // AllOrdersRequest represents AllOrders request data.
+// OrderID不为0时返回订单号不小于OrderID的订单，否则按StartTime、EndTime筛选，
+// StartTime和EndTime之间不能超过24小时
 type AllOrdersRequest struct {
 	Symbol     string
 	OrderID    int64
-	Limit      int
+	StartTime  time.Time
+	EndTime    time.Time
+	Limit      int // 默认500，最大1000
 	RecvWindow time.Duration
 	Timestamp  time.Time
 }
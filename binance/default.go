@@ -0,0 +1,48 @@
+package binance
+
+import (
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/clean"
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/gorilla/websocket"
+)
+
+func defaultConfig() *config.Config {
+	cfg := &config.Config{}
+
+	cfg.WithRESTHost("api.binance.com")
+	cfg.WithWSSHost("stream.binance.com:9443")
+	cfg.WithAPIKey("")
+	cfg.WithSecret("")
+	cfg.WithHTTPClient(clean.DefaultPooledClient())
+	cfg.WithWSSDialer(websocket.DefaultDialer)
+	cfg.WithUseSSL(true)
+	cfg.WithTimeout(5 * time.Second)
+
+	return cfg
+}
+
+// testnetConfig 现货测试网 https://testnet.binance.vision
+func testnetConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.WithRESTHost("testnet.binance.vision")
+	cfg.WithWSSHost("testnet.binance.vision")
+	return cfg
+}
+
+// futuresDefaultConfig U本位合约
+func futuresDefaultConfig() *config.Config {
+	cfg := defaultConfig()
+	cfg.WithRESTHost("fapi.binance.com")
+	cfg.WithWSSHost("fstream.binance.com")
+	return cfg
+}
+
+// futuresTestnetConfig U本位合约测试网 https://testnet.binancefuture.com
+func futuresTestnetConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.WithRESTHost("testnet.binancefuture.com")
+	cfg.WithWSSHost("stream.binancefuture.com")
+	return cfg
+}
@@ -0,0 +1,80 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单、撤单、批量撤单、提现以及合约调整杠杆和保证金模式的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	q := req.URL.Query()
+	var rsp interface{}
+	switch {
+	case req.Method == "POST" && req.URL.Path == "/api/v3/order":
+		clientID := q.Get("newClientOrderId")
+		if clientID == "" {
+			clientID = "dryrun"
+		}
+		rsp = map[string]interface{}{
+			"symbol":        q.Get("symbol"),
+			"orderId":       config.DryRunOrderID(),
+			"clientOrderId": clientID,
+			"transactTime":  time.Now().UnixNano() / int64(time.Millisecond),
+		}
+	case req.Method == "DELETE" && req.URL.Path == "/api/v3/order":
+		orderID, _ := strconv.ParseInt(q.Get("orderId"), 10, 64)
+		rsp = map[string]interface{}{
+			"symbol":            q.Get("symbol"),
+			"origClientOrderId": q.Get("origClientOrderId"),
+			"orderId":           orderID,
+			"clientOrderId":     "dryrun",
+		}
+	case req.Method == "POST" && req.URL.Path == "/fapi/v1/order":
+		clientID := q.Get("newClientOrderId")
+		if clientID == "" {
+			clientID = "dryrun"
+		}
+		rsp = map[string]interface{}{
+			"symbol":        q.Get("symbol"),
+			"orderId":       config.DryRunOrderID(),
+			"clientOrderId": clientID,
+			"status":        "NEW",
+			"side":          q.Get("side"),
+			"positionSide":  q.Get("positionSide"),
+			"type":          q.Get("type"),
+			"price":         q.Get("price"),
+			"origQty":       q.Get("quantity"),
+			"reduceOnly":    q.Get("reduceOnly") == "true",
+			"updateTime":    time.Now().UnixNano() / int64(time.Millisecond),
+		}
+	case req.Method == "DELETE" && req.URL.Path == "/fapi/v1/order":
+		orderID, _ := strconv.ParseInt(q.Get("orderId"), 10, 64)
+		rsp = map[string]interface{}{
+			"symbol":        q.Get("symbol"),
+			"orderId":       orderID,
+			"clientOrderId": "dryrun",
+			"status":        "CANCELED",
+		}
+	case req.Method == "POST" && req.URL.Path == "/fapi/v1/leverage":
+		leverage, _ := strconv.Atoi(q.Get("leverage"))
+		rsp = map[string]interface{}{
+			"symbol":           q.Get("symbol"),
+			"leverage":         leverage,
+			"maxNotionalValue": "0",
+		}
+	case req.Method == "POST" && req.URL.Path == "/fapi/v1/marginType":
+		rsp = map[string]interface{}{"code": 200, "msg": "success"}
+	case req.Method == "DELETE" && req.URL.Path == "/api/v3/openOrders":
+		rsp = []interface{}{}
+	case req.Method == "POST" && req.URL.Path == "/wapi/v1/withdraw.html":
+		rsp = map[string]interface{}{"msg": "dry run", "success": true, "id": "dryrun"}
+	default:
+		return nil, false
+	}
+	data, _ := json.Marshal(rsp)
+	return data, true
+}
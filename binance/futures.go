@@ -0,0 +1,463 @@
+package binance
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+// PositionSide 持仓方向，单向持仓模式下为BOTH
+type PositionSide string
+
+// MarginType 保证金模式
+type MarginType string
+
+var (
+	PositionBoth  = PositionSide("BOTH")
+	PositionLong  = PositionSide("LONG")
+	PositionShort = PositionSide("SHORT")
+
+	MarginIsolated = MarginType("ISOLATED")
+	MarginCrossed  = MarginType("CROSSED")
+)
+
+// FuturesService U本位合约(USDⓈ-M)接口，与现货共用签名和请求流程
+type FuturesService interface {
+	// GetAllSymbol 所有的合约
+	GetAllSymbol() ([]global.TradeSymbol, error)
+	// Positions 查询持仓，symbol为空时返回所有合约
+	Positions(symbol string) ([]*Position, error)
+	// ChangeLeverage 调整合约的杠杆倍数
+	ChangeLeverage(symbol string, leverage int) (*Leverage, error)
+	// ChangeMarginType 调整合约的保证金模式，已经是该模式时不返回错误
+	ChangeMarginType(symbol string, marginType MarginType) error
+	// InsertFuturesOrder 下单
+	InsertFuturesOrder(FuturesOrderRequest) (*FuturesOrder, error)
+	// FuturesOrderStatus 查询订单，orderID为0时按clientOrderID查询
+	FuturesOrderStatus(symbol string, orderID int64, clientOrderID string) (*FuturesOrder, error)
+	// CancelFuturesOrder 撤单
+	CancelFuturesOrder(symbol string, orderID int64) (*FuturesOrder, error)
+	// FundingRateHistory 查询资金费率历史
+	FundingRateHistory(FundingRateRequest) ([]*FundingRate, error)
+	// MarkPrice 查询标记价格和当前资金费率
+	MarkPrice(symbol string) (*MarkPrice, error)
+
+	// MarkPriceWebsocket 订阅标记价格，speed可选Speed1s、Speed3s，为空时3s
+	MarkPriceWebsocket(symbol string, speed UpdateSpeed) (chan *MarkPriceEvent, error)
+	// ForceOrderWebsocket 订阅强平订单，symbol为空时订阅所有合约
+	ForceOrderWebsocket(symbol string) (chan *ForceOrderEvent, error)
+	// 取消订阅行情stream，如btcusdt@markPrice
+	UnsubscribeStream(streams ...string) error
+	// 托管的用户数据流，ORDER_TRADE_UPDATE转换为订单变动，ACCOUNT_UPDATE转换为资金和持仓变动
+	SubOrderUpdate() (chan global.OrderUpdate, error)
+	SubBalanceUpdate() (chan global.BalanceUpdate, error)
+	SubPositionUpdate() (chan *PositionUpdate, error)
+}
+
+// Position 持仓
+type Position struct {
+	Symbol           string
+	PositionSide     PositionSide
+	Amount           float64 // 持仓数量，空头为负数
+	EntryPrice       float64
+	MarkPrice        float64
+	UnrealizedProfit float64
+	LiquidationPrice float64
+	Leverage         int
+	MarginType       MarginType
+	IsolatedMargin   float64
+	UpdateTime       time.Time
+}
+
+// Leverage 调整杠杆的结果
+type Leverage struct {
+	Symbol           string
+	Leverage         int
+	MaxNotionalValue float64
+}
+
+// FuturesOrderRequest 合约下单参数
+type FuturesOrderRequest struct {
+	Symbol        string
+	Side          OrderSide
+	PositionSide  PositionSide // 双向持仓模式下必填
+	Type          OrderType
+	TimeInForce   TimeInForce // 限价单为空时默认GTC
+	Quantity      float64
+	Price         float64
+	StopPrice     float64
+	ReduceOnly    bool // 只减仓，双向持仓模式下不可用
+	ClosePosition bool // 触发后全部平仓，与Quantity互斥
+	ClientOrderID string
+}
+
+// FuturesOrder 合约订单
+type FuturesOrder struct {
+	Symbol        string
+	OrderID       int64
+	ClientOrderID string
+	Price         float64
+	AvgPrice      float64
+	OrigQty       float64
+	ExecutedQty   float64
+	CumQuote      float64
+	Status        OrderStatus
+	TimeInForce   TimeInForce
+	Type          OrderType
+	Side          OrderSide
+	PositionSide  PositionSide
+	ReduceOnly    bool
+	StopPrice     float64
+	UpdateTime    time.Time
+}
+
+// FundingRateRequest 资金费率历史的查询参数，StartTime、EndTime为零值时不限制
+type FundingRateRequest struct {
+	Symbol    string
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int // 默认100，最大1000
+}
+
+// FundingRate 一次资金费率结算
+type FundingRate struct {
+	Symbol      string
+	FundingRate float64
+	FundingTime time.Time
+}
+
+// MarkPrice 标记价格和资金费率
+type MarkPrice struct {
+	Symbol          string
+	MarkPrice       float64
+	IndexPrice      float64
+	LastFundingRate float64
+	NextFundingTime time.Time
+	Time            time.Time
+}
+
+var futuresPaths = apiPaths{
+	time:         "fapi/v1/time",
+	exchangeInfo: "fapi/v1/exchangeInfo",
+	listenKey:    "fapi/v1/listenKey",
+}
+
+type futuresService struct {
+	*apiService
+}
+
+// NewFuturesClient 使用config创建U本位合约的client
+func NewFuturesClient(config *config.Config) FuturesService {
+	cfg := futuresDefaultConfig()
+	if config != nil {
+		cfg.MergeInEnv(futuresTestnetConfig(), config)
+	}
+	return &futuresService{newAPIService(cfg, futuresPaths)}
+}
+
+type rawPosition struct {
+	Symbol           string `json:"symbol"`
+	PositionAmt      string `json:"positionAmt"`
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+	LiquidationPrice string `json:"liquidationPrice"`
+	Leverage         string `json:"leverage"`
+	MarginType       string `json:"marginType"` // cross、isolated
+	IsolatedMargin   string `json:"isolatedMargin"`
+	PositionSide     string `json:"positionSide"`
+	UpdateTime       int64  `json:"updateTime"`
+}
+
+func (fs *futuresService) Positions(symbol string) ([]*Position, error) {
+	params := make(map[string]string)
+	if symbol != "" {
+		params["symbol"] = strings.ToUpper(symbol)
+	}
+	params["timestamp"] = strconv.FormatInt(unixMillis(fs.clock.Now()), 10)
+	var raws []rawPosition
+	err := fs.requestRetry("GET", "fapi/v2/positionRisk", params, &raws, true, true)
+	if err != nil {
+		return nil, err
+	}
+	var ps []*Position
+	for _, r := range raws {
+		p := &Position{
+			Symbol:       r.Symbol,
+			PositionSide: PositionSide(r.PositionSide),
+			MarginType:   MarginCrossed,
+			UpdateTime:   time.Unix(0, r.UpdateTime*int64(time.Millisecond)),
+		}
+		if r.MarginType == "isolated" {
+			p.MarginType = MarginIsolated
+		}
+		p.Amount, _ = strconv.ParseFloat(r.PositionAmt, 64)
+		p.EntryPrice, _ = strconv.ParseFloat(r.EntryPrice, 64)
+		p.MarkPrice, _ = strconv.ParseFloat(r.MarkPrice, 64)
+		p.UnrealizedProfit, _ = strconv.ParseFloat(r.UnRealizedProfit, 64)
+		p.LiquidationPrice, _ = strconv.ParseFloat(r.LiquidationPrice, 64)
+		p.Leverage, _ = strconv.Atoi(r.Leverage)
+		p.IsolatedMargin, _ = strconv.ParseFloat(r.IsolatedMargin, 64)
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+func (fs *futuresService) ChangeLeverage(symbol string, leverage int) (*Leverage, error) {
+	params := make(map[string]string)
+	params["symbol"] = strings.ToUpper(symbol)
+	params["leverage"] = strconv.Itoa(leverage)
+	params["timestamp"] = strconv.FormatInt(unixMillis(fs.clock.Now()), 10)
+	r := struct {
+		Symbol           string `json:"symbol"`
+		Leverage         int    `json:"leverage"`
+		MaxNotionalValue string `json:"maxNotionalValue"`
+	}{}
+	// 设置为相同的值，可以重试
+	err := fs.requestRetry("POST", "fapi/v1/leverage", params, &r, true, true)
+	if err != nil {
+		return nil, err
+	}
+	l := &Leverage{Symbol: r.Symbol, Leverage: r.Leverage}
+	l.MaxNotionalValue, _ = strconv.ParseFloat(r.MaxNotionalValue, 64)
+	return l, nil
+}
+
+func (fs *futuresService) ChangeMarginType(symbol string, marginType MarginType) error {
+	params := make(map[string]string)
+	params["symbol"] = strings.ToUpper(symbol)
+	params["marginType"] = string(marginType)
+	params["timestamp"] = strconv.FormatInt(unixMillis(fs.clock.Now()), 10)
+	r := struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}{}
+	err := fs.requestRetry("POST", "fapi/v1/marginType", params, &r, true, true)
+	if e, ok := err.(*Error); ok && e.Code == -4046 {
+		// No need to change margin type.
+		return nil
+	}
+	return err
+}
+
+type rawFuturesOrder struct {
+	Symbol        string `json:"symbol"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	CumQuote      string `json:"cumQuote"`
+	Status        string `json:"status"`
+	TimeInForce   string `json:"timeInForce"`
+	Type          string `json:"type"`
+	Side          string `json:"side"`
+	PositionSide  string `json:"positionSide"`
+	ReduceOnly    bool   `json:"reduceOnly"`
+	StopPrice     string `json:"stopPrice"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+func (r *rawFuturesOrder) toOrder() *FuturesOrder {
+	o := &FuturesOrder{
+		Symbol:        r.Symbol,
+		OrderID:       r.OrderID,
+		ClientOrderID: r.ClientOrderID,
+		Status:        OrderStatus(r.Status),
+		TimeInForce:   TimeInForce(r.TimeInForce),
+		Type:          OrderType(r.Type),
+		Side:          OrderSide(r.Side),
+		PositionSide:  PositionSide(r.PositionSide),
+		ReduceOnly:    r.ReduceOnly,
+		UpdateTime:    time.Unix(0, r.UpdateTime*int64(time.Millisecond)),
+	}
+	o.Price, _ = strconv.ParseFloat(r.Price, 64)
+	o.AvgPrice, _ = strconv.ParseFloat(r.AvgPrice, 64)
+	o.OrigQty, _ = strconv.ParseFloat(r.OrigQty, 64)
+	o.ExecutedQty, _ = strconv.ParseFloat(r.ExecutedQty, 64)
+	o.CumQuote, _ = strconv.ParseFloat(r.CumQuote, 64)
+	o.StopPrice, _ = strconv.ParseFloat(r.StopPrice, 64)
+	return o
+}
+
+func (fs *futuresService) InsertFuturesOrder(or FuturesOrderRequest) (*FuturesOrder, error) {
+	params := make(map[string]string)
+	params["symbol"] = strings.ToUpper(or.Symbol)
+	params["side"] = string(or.Side)
+	params["type"] = string(or.Type)
+	if or.PositionSide != "" {
+		params["positionSide"] = string(or.PositionSide)
+	}
+	if or.Type == TypeLimit {
+		tif := or.TimeInForce
+		if tif == "" {
+			tif = GTC
+		}
+		params["timeInForce"] = string(tif)
+	}
+	if or.Quantity != 0 {
+		params["quantity"] = strconv.FormatFloat(or.Quantity, 'f', -1, 64)
+	}
+	if or.Price != 0 {
+		params["price"] = strconv.FormatFloat(or.Price, 'f', -1, 64)
+	}
+	if or.StopPrice != 0 {
+		params["stopPrice"] = strconv.FormatFloat(or.StopPrice, 'f', -1, 64)
+	}
+	if or.ReduceOnly {
+		params["reduceOnly"] = "true"
+	}
+	if or.ClosePosition {
+		params["closePosition"] = "true"
+	}
+	if or.ClientOrderID != "" {
+		params["newClientOrderId"] = or.ClientOrderID
+	}
+	var raw rawFuturesOrder
+	place := func() error {
+		params["timestamp"] = strconv.FormatInt(unixMillis(fs.clock.Now()), 10)
+		return fs.request("POST", "fapi/v1/order", params, &raw, true, true)
+	}
+	// 只有带上客户端订单号时才能确认下单是否已经生效，才允许重试
+	var lookup func() (bool, error)
+	if or.ClientOrderID != "" {
+		lookup = func() (bool, error) {
+			o, err := fs.queryOrder(params["symbol"], 0, or.ClientOrderID)
+			if e, ok := err.(*Error); ok && e.Code == -2013 {
+				// Order does not exist.
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			raw = *o
+			return true, nil
+		}
+	}
+	if err := fs.config.RetryDoOrder("binance", place, lookup); err != nil {
+		return nil, err
+	}
+	return raw.toOrder(), nil
+}
+
+func (fs *futuresService) FuturesOrderStatus(symbol string, orderID int64, clientOrderID string) (*FuturesOrder, error) {
+	var raw *rawFuturesOrder
+	err := fs.config.RetryDo("binance", func() error {
+		var e error
+		raw, e = fs.queryOrder(symbol, orderID, clientOrderID)
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+	return raw.toOrder(), nil
+}
+
+func (fs *futuresService) queryOrder(symbol string, orderID int64, clientOrderID string) (*rawFuturesOrder, error) {
+	params := make(map[string]string)
+	params["symbol"] = strings.ToUpper(symbol)
+	if orderID != 0 {
+		params["orderId"] = strconv.FormatInt(orderID, 10)
+	} else {
+		params["origClientOrderId"] = clientOrderID
+	}
+	params["timestamp"] = strconv.FormatInt(unixMillis(fs.clock.Now()), 10)
+	var raw rawFuturesOrder
+	if err := fs.request("GET", "fapi/v1/order", params, &raw, true, true); err != nil {
+		return nil, err
+	}
+	return &raw, nil
+}
+
+func (fs *futuresService) CancelFuturesOrder(symbol string, orderID int64) (*FuturesOrder, error) {
+	params := make(map[string]string)
+	params["symbol"] = strings.ToUpper(symbol)
+	params["orderId"] = strconv.FormatInt(orderID, 10)
+	var raw rawFuturesOrder
+	cancel := func() error {
+		params["timestamp"] = strconv.FormatInt(unixMillis(fs.clock.Now()), 10)
+		return fs.request("DELETE", "fapi/v1/order", params, &raw, true, true)
+	}
+	// 撤单成功但响应丢失时，重发会返回-2011 Unknown order，因此重试前先查询订单，
+	// 已经撤销时直接返回成功
+	lookup := func() (bool, error) {
+		o, err := fs.queryOrder(params["symbol"], orderID, "")
+		if err != nil {
+			return false, err
+		}
+		if OrderStatus(o.Status) != StatusCancelled {
+			return false, nil
+		}
+		raw = *o
+		return true, nil
+	}
+	if err := fs.config.RetryDoOrder("binance", cancel, lookup); err != nil {
+		return nil, err
+	}
+	return raw.toOrder(), nil
+}
+
+func (fs *futuresService) FundingRateHistory(fr FundingRateRequest) ([]*FundingRate, error) {
+	params := make(map[string]string)
+	if fr.Symbol != "" {
+		params["symbol"] = strings.ToUpper(fr.Symbol)
+	}
+	if !fr.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(unixMillis(fr.StartTime), 10)
+	}
+	if !fr.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(unixMillis(fr.EndTime), 10)
+	}
+	if fr.Limit != 0 {
+		params["limit"] = strconv.Itoa(fr.Limit)
+	}
+	var raws []struct {
+		Symbol      string `json:"symbol"`
+		FundingRate string `json:"fundingRate"`
+		FundingTime int64  `json:"fundingTime"`
+	}
+	err := fs.requestRetry("GET", "fapi/v1/fundingRate", params, &raws, false, false)
+	if err != nil {
+		return nil, err
+	}
+	var rs []*FundingRate
+	for _, r := range raws {
+		f := &FundingRate{
+			Symbol:      r.Symbol,
+			FundingTime: time.Unix(0, r.FundingTime*int64(time.Millisecond)),
+		}
+		f.FundingRate, _ = strconv.ParseFloat(r.FundingRate, 64)
+		rs = append(rs, f)
+	}
+	return rs, nil
+}
+
+func (fs *futuresService) MarkPrice(symbol string) (*MarkPrice, error) {
+	params := make(map[string]string)
+	params["symbol"] = strings.ToUpper(symbol)
+	r := struct {
+		Symbol          string `json:"symbol"`
+		MarkPrice       string `json:"markPrice"`
+		IndexPrice      string `json:"indexPrice"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+		Time            int64  `json:"time"`
+	}{}
+	err := fs.requestRetry("GET", "fapi/v1/premiumIndex", params, &r, false, false)
+	if err != nil {
+		return nil, err
+	}
+	m := &MarkPrice{
+		Symbol:          r.Symbol,
+		NextFundingTime: time.Unix(0, r.NextFundingTime*int64(time.Millisecond)),
+		Time:            time.Unix(0, r.Time*int64(time.Millisecond)),
+	}
+	m.MarkPrice, _ = strconv.ParseFloat(r.MarkPrice, 64)
+	m.IndexPrice, _ = strconv.ParseFloat(r.IndexPrice, 64)
+	m.LastFundingRate, _ = strconv.ParseFloat(r.LastFundingRate, 64)
+	return m, nil
+}
@@ -0,0 +1,294 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
+)
+
+// MarkPriceEvent markPrice推送
+type MarkPriceEvent struct {
+	WSEvent
+	MarkPrice       float64
+	IndexPrice      float64
+	FundingRate     float64
+	NextFundingTime time.Time
+}
+
+// ForceOrderEvent forceOrder推送的强平订单
+type ForceOrderEvent struct {
+	WSEvent
+	Side        OrderSide
+	Type        OrderType
+	Status      OrderStatus
+	Price       float64
+	AvgPrice    float64
+	OrigQty     float64
+	ExecutedQty float64
+	TradeTime   time.Time
+}
+
+// PositionUpdate 用户数据流ACCOUNT_UPDATE中的持仓变动
+type PositionUpdate struct {
+	Symbol           string
+	PositionSide     PositionSide
+	Amount           float64
+	EntryPrice       float64
+	UnrealizedProfit float64
+	MarginType       MarginType
+	IsolatedWallet   float64
+	Reason           string // 变动原因，如ORDER、FUNDING_FEE
+	Time             time.Time
+}
+
+func (fs *futuresService) MarkPriceWebsocket(symbol string, speed UpdateSpeed) (chan *MarkPriceEvent, error) {
+	stream := strings.ToLower(symbol) + "@markPrice"
+	switch speed {
+	case "", Speed3s:
+	case Speed1s:
+		stream += "@" + string(speed)
+	default:
+		return nil, fmt.Errorf("binance mark price update speed must be 1s or 3s, got %s", speed)
+	}
+	mech := make(chan *MarkPriceEvent, 100)
+	err := fs.subscribe(stream, func(message []byte) {
+		raw := struct {
+			Type            string `json:"e"`
+			Time            int64  `json:"E"`
+			Symbol          string `json:"s"`
+			MarkPrice       string `json:"p"`
+			IndexPrice      string `json:"i"`
+			SettlePrice     string `json:"P"`
+			FundingRate     string `json:"r"`
+			NextFundingTime int64  `json:"T"`
+		}{}
+		if err := json.Unmarshal(message, &raw); err != nil {
+			fs.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(message))
+			return
+		}
+		me := &MarkPriceEvent{
+			WSEvent: WSEvent{
+				Type:   raw.Type,
+				Time:   time.Unix(0, raw.Time*int64(time.Millisecond)),
+				Symbol: raw.Symbol,
+			},
+			NextFundingTime: time.Unix(0, raw.NextFundingTime*int64(time.Millisecond)),
+		}
+		me.MarkPrice, _ = strconv.ParseFloat(raw.MarkPrice, 64)
+		me.IndexPrice, _ = strconv.ParseFloat(raw.IndexPrice, 64)
+		me.FundingRate, _ = strconv.ParseFloat(raw.FundingRate, 64)
+		fs.config.ObserveMessage("binance", stream)
+		select {
+		case mech <- me:
+		default:
+			fs.config.ObserveDrop("binance", stream)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mech, nil
+}
+
+func (fs *futuresService) ForceOrderWebsocket(symbol string) (chan *ForceOrderEvent, error) {
+	stream := "!forceOrder@arr"
+	if symbol != "" {
+		stream = strings.ToLower(symbol) + "@forceOrder"
+	}
+	fech := make(chan *ForceOrderEvent, 100)
+	err := fs.subscribe(stream, func(message []byte) {
+		raw := struct {
+			Type  string `json:"e"`
+			Time  int64  `json:"E"`
+			Order struct {
+				Symbol      string `json:"s"`
+				Side        string `json:"S"`
+				Type        string `json:"o"`
+				Price       string `json:"p"`
+				AvgPrice    string `json:"ap"`
+				OrigQty     string `json:"q"`
+				Status      string `json:"X"`
+				ExecutedQty string `json:"z"`
+				TradeTime   int64  `json:"T"`
+			} `json:"o"`
+		}{}
+		if err := json.Unmarshal(message, &raw); err != nil {
+			fs.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(message))
+			return
+		}
+		o := raw.Order
+		fe := &ForceOrderEvent{
+			WSEvent: WSEvent{
+				Type:   raw.Type,
+				Time:   time.Unix(0, raw.Time*int64(time.Millisecond)),
+				Symbol: o.Symbol,
+			},
+			Side:      OrderSide(o.Side),
+			Type:      OrderType(o.Type),
+			Status:    OrderStatus(o.Status),
+			TradeTime: time.Unix(0, o.TradeTime*int64(time.Millisecond)),
+		}
+		fe.Price, _ = strconv.ParseFloat(o.Price, 64)
+		fe.AvgPrice, _ = strconv.ParseFloat(o.AvgPrice, 64)
+		fe.OrigQty, _ = strconv.ParseFloat(o.OrigQty, 64)
+		fe.ExecutedQty, _ = strconv.ParseFloat(o.ExecutedQty, 64)
+		fs.config.ObserveMessage("binance", stream)
+		select {
+		case fech <- fe:
+		default:
+			fs.config.ObserveDrop("binance", stream)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fech, nil
+}
+
+// SubPositionUpdate 通过用户数据流订阅持仓变动
+func (fs *futuresService) SubPositionUpdate() (chan *PositionUpdate, error) {
+	s, err := fs.userStreamStart()
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.positions == nil {
+		s.positions = make(chan *PositionUpdate, 100)
+	}
+	return s.positions, nil
+}
+
+// pushPosition 推送持仓变动，规则同pushOrder
+func (as *apiService) pushPosition(u *PositionUpdate) {
+	s := as.stream
+	s.mutex.Lock()
+	ch := s.positions
+	s.mutex.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- u:
+	default:
+		as.config.ObserveDrop("binance", "userdata:positions")
+	}
+}
+
+// futuresUserEvent 合约用户数据流的ORDER_TRADE_UPDATE和ACCOUNT_UPDATE
+type futuresUserEvent struct {
+	Type  string `json:"e"`
+	Time  int64  `json:"E"`
+	Order struct {
+		Symbol        string `json:"s"`
+		ClientOrderID string `json:"c"`
+		Side          string `json:"S"`
+		OrderType     string `json:"o"`
+		Qty           string `json:"q"`
+		Price         string `json:"p"`
+		ExecType      string `json:"x"`
+		Status        string `json:"X"`
+		OrderID       int64  `json:"i"`
+		LastQty       string `json:"l"`
+		LastPrice     string `json:"L"`
+		Commission    string `json:"n"`
+		CommissionAs  string `json:"N"`
+		TradeTime     int64  `json:"T"`
+		// 不使用，但必须声明，否则encoding/json会忽略大小写把它填入TradeTime
+		TradeID int64 `json:"t"`
+	} `json:"o"`
+	Account struct {
+		Reason   string `json:"m"`
+		Balances []struct {
+			Asset        string `json:"a"`
+			Wallet       string `json:"wb"`
+			CrossWallet  string `json:"cw"`
+			BalanceDelta string `json:"bc"`
+		} `json:"B"`
+		Positions []struct {
+			Symbol         string `json:"s"`
+			Amount         string `json:"pa"`
+			EntryPrice     string `json:"ep"`
+			Unrealized     string `json:"up"`
+			MarginType     string `json:"mt"` // cross、isolated
+			IsolatedWallet string `json:"iw"`
+			PositionSide   string `json:"ps"`
+		} `json:"P"`
+	} `json:"a"`
+}
+
+// futuresUserParse 解析合约用户数据流的推送
+func (as *apiService) futuresUserParse(msg []byte) {
+	s := as.stream
+	var e futuresUserEvent
+	if err := json.Unmarshal(msg, &e); err != nil {
+		as.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(msg))
+		return
+	}
+	as.config.ObserveMessage("binance", "userdata:"+e.Type)
+	if e.Type == "ORDER_TRADE_UPDATE" {
+		o := e.Order
+		s.mutex.Lock()
+		key, ok := s.symbols[o.Symbol]
+		s.mutex.Unlock()
+		if !ok {
+			key = global.TradeSymbol{Base: o.Symbol}
+		}
+		u := global.OrderUpdate{
+			Base:          key.Base,
+			Quote:         key.Quote,
+			OrderNo:       strconv.FormatInt(o.OrderID, 10),
+			ClientOrderID: o.ClientOrderID,
+			Status:        orderStatus(OrderStatus(o.Status)),
+			Timestamp:     e.Time,
+		}
+		if OrderSide(o.Side) == SideSell {
+			u.Direction = 1
+		}
+		if OrderType(o.OrderType) == TypeMarket {
+			u.Type = 1
+		}
+		u.Price, _ = strconv.ParseFloat(o.Price, 64)
+		u.Num, _ = strconv.ParseFloat(o.Qty, 64)
+		if o.ExecType == "TRADE" {
+			u.TradePrice, _ = strconv.ParseFloat(o.LastPrice, 64)
+			u.TradeNum, _ = strconv.ParseFloat(o.LastQty, 64)
+			u.Fee, _ = strconv.ParseFloat(o.Commission, 64)
+			u.FeeAsset = o.CommissionAs
+			u.Timestamp = o.TradeTime
+		}
+		as.pushOrder(u)
+		return
+	}
+
+	// ACCOUNT_UPDATE，可用为全仓钱包余额，冻结为逐仓占用的部分
+	for _, b := range e.Account.Balances {
+		u := global.BalanceUpdate{Base: b.Asset, Timestamp: e.Time}
+		wallet, _ := strconv.ParseFloat(b.Wallet, 64)
+		u.Available, _ = strconv.ParseFloat(b.CrossWallet, 64)
+		u.Frozen = wallet - u.Available
+		as.pushBalance(u)
+	}
+	for _, p := range e.Account.Positions {
+		u := &PositionUpdate{
+			Symbol:       p.Symbol,
+			PositionSide: PositionSide(p.PositionSide),
+			MarginType:   MarginCrossed,
+			Reason:       e.Account.Reason,
+			Time:         time.Unix(0, e.Time*int64(time.Millisecond)),
+		}
+		if p.MarginType == "isolated" {
+			u.MarginType = MarginIsolated
+		}
+		u.Amount, _ = strconv.ParseFloat(p.Amount, 64)
+		u.EntryPrice, _ = strconv.ParseFloat(p.EntryPrice, 64)
+		u.UnrealizedProfit, _ = strconv.ParseFloat(p.Unrealized, 64)
+		u.IsolatedWallet, _ = strconv.ParseFloat(p.IsolatedWallet, 64)
+		as.pushPosition(u)
+	}
+}
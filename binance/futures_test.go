@@ -0,0 +1,47 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// TestCancelFuturesOrderLostResponse 撤单已经成功但响应丢失时，重试前查询到已撤销直接返回成功
+func TestCancelFuturesOrderLostResponse(t *testing.T) {
+	var deletes int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/order" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case "DELETE":
+			if atomic.AddInt32(&deletes, 1) == 1 {
+				// 订单已经撤销，但客户端没有收到响应
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-2011,"msg":"Unknown order sent."}`))
+		case "GET":
+			w.Write([]byte(`{"symbol":"BTCUSDT","orderId":42,"status":"CANCELED","side":"BUY","type":"LIMIT"}`))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(srv).WithRetryPolicy(&config.RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond})
+	o, err := NewFuturesClient(cfg).CancelFuturesOrder("btcusdt", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.OrderID != 42 || o.Status != StatusCancelled {
+		t.Errorf("unexpected order %+v", o)
+	}
+	if n := atomic.LoadInt32(&deletes); n != 1 {
+		t.Errorf("cancel sent %d times, want 1", n)
+	}
+}
@@ -0,0 +1,146 @@
+package binance
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+const (
+	// allOrders按时间查询时，startTime和endTime之间最多24小时
+	maxOrderWindow = 24 * time.Hour
+	maxOrderLimit  = 1000
+)
+
+// OrderIterator 按下单时间顺序遍历一段时间内的历史订单
+// 时间范围按24小时拆分为多个窗口，窗口内订单过多时继续分页，用法:
+//
+//	it := client.OrderHistory(sreq, start, end)
+//	for it.Next() {
+//		o := it.Order()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type OrderIterator struct {
+	as  *apiService
+	key global.TradeSymbol
+	end time.Time
+
+	window time.Time // 当前窗口的开始时间
+	from   time.Time // 当前窗口内下一页的开始时间
+	lastID int       // 已经返回的最大订单号，用于翻页时去重
+	buf    []*ExecutedOrder
+	cur    *ExecutedOrder
+	err    error
+}
+
+// OrderHistory 返回遍历[start, end)内历史订单的迭代器，end为零值时遍历到当前时间
+func (as *apiService) OrderHistory(sreq global.TradeSymbol, start, end time.Time) *OrderIterator {
+	if end.IsZero() {
+		end = as.clock.Now()
+	}
+	return &OrderIterator{
+		as:     as,
+		key:    sreq,
+		end:    end,
+		window: start,
+		from:   start,
+	}
+}
+
+// Next 移动到下一个订单，没有更多订单或出错时返回false
+func (it *OrderIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil || !it.window.Before(it.end) {
+			it.cur = nil
+			return false
+		}
+		it.fetch()
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// fetch 查询当前窗口的下一页，窗口内没有更多订单时移动到下一个窗口
+func (it *OrderIterator) fetch() {
+	windowEnd := it.window.Add(maxOrderWindow)
+	if windowEnd.After(it.end) {
+		windowEnd = it.end
+	}
+	orders, err := it.as.AllOrders(AllOrdersRequest{
+		Symbol:    strings.ToUpper(it.key.Base + it.key.Quote),
+		StartTime: it.from,
+		EndTime:   windowEnd.Add(-time.Millisecond), // endTime包含在内
+		Limit:     maxOrderLimit,
+	})
+	if err != nil {
+		it.err = err
+		return
+	}
+	for _, o := range orders {
+		// 下一页从上一页最后一个订单的时间开始，同一毫秒内的订单可能重复返回
+		if o.OrderID > it.lastID {
+			it.buf = append(it.buf, o)
+			it.lastID = o.OrderID
+		}
+	}
+	switch {
+	case len(orders) < maxOrderLimit:
+		it.window = windowEnd
+		it.from = windowEnd
+	case len(it.buf) == 0:
+		// 整页都在同一毫秒内，跳过这一毫秒以免死循环
+		it.from = it.from.Add(time.Millisecond)
+	default:
+		it.from = orders[len(orders)-1].Time
+	}
+}
+
+// Order 返回当前订单
+func (it *OrderIterator) Order() *ExecutedOrder {
+	return it.cur
+}
+
+// Unified 返回当前订单的统一格式
+func (it *OrderIterator) Unified() global.Order {
+	return unifiedOrder(it.key, it.cur)
+}
+
+// Err 返回遍历过程中的错误
+func (it *OrderIterator) Err() error {
+	return it.err
+}
+
+// unifiedOrder 将ExecutedOrder转换为global.Order
+func unifiedOrder(key global.TradeSymbol, eo *ExecutedOrder) global.Order {
+	o := global.Order{
+		Base:          key.Base,
+		Quote:         key.Quote,
+		OrderNo:       strconv.Itoa(eo.OrderID),
+		ClientOrderID: eo.ClientOrderID,
+		Price:         eo.Price,
+		Num:           eo.OrigQty,
+		TradeNum:      eo.ExecutedQty,
+		Status:        orderStatus(eo.Status),
+		Timestamp:     unixMillis(eo.Time),
+	}
+	if eo.Side == SideSell {
+		o.Direction = 1
+	}
+	if eo.Type == TypeMarket {
+		o.Type = 1
+	}
+	o.TradePrice = eo.avgPrice()
+	return o
+}
+
+// avgPrice 返回成交均价，没有成交时为0
+func (eo *ExecutedOrder) avgPrice() float64 {
+	if eo.ExecutedQty == 0 {
+		return 0
+	}
+	// ExecutePrice为累计成交金额
+	return eo.ExecutePrice / eo.ExecutedQty
+}
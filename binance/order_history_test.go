@@ -0,0 +1,48 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+const testExecutedOrder = `{"symbol":"LTCBTC","orderId":1,"clientOrderId":"myOrder1","price":"0.1","origQty":"1.0",
+	"executedQty":"0.5","cummulativeQuoteQty":"0.06","status":"PARTIALLY_FILLED","timeInForce":"GTC",
+	"type":"LIMIT","side":"SELL","stopPrice":"0.0","icebergQty":"0.0","time":1499827319559}`
+
+func TestUnifiedOrderTradePrice(t *testing.T) {
+	body := []byte(testExecutedOrder)
+	var raw rawExecutedOrder
+	if err := json.Unmarshal(body, &raw); err != nil {
+		t.Fatal(err)
+	}
+	eo, err := executedOrderFromRaw(&raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eo.ExecutePrice != 0.06 {
+		t.Fatalf("ExecutePrice %v, want 0.06", eo.ExecutePrice)
+	}
+	o := unifiedOrder(global.TradeSymbol{Base: "LTC", Quote: "BTC"}, eo)
+	if o.TradePrice < 0.1199999 || o.TradePrice > 0.1200001 {
+		t.Errorf("TradePrice %v, want 0.12", o.TradePrice)
+	}
+	if o.TradeNum != 0.5 || o.Direction != 1 || o.Status != global.HALFTRADE {
+		t.Errorf("unexpected order %+v", o)
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[" + testExecutedOrder + "]"))
+	}))
+	defer srv.Close()
+	rsp, err := newTestClient(srv).OrderStatus(global.StatusReq{Base: "LTC", Quote: "BTC", OrderNo: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.TradePrice != o.TradePrice || rsp.TradeNum != 0.5 || rsp.Status != global.HALFTRADE {
+		t.Errorf("OrderStatus %+v, want TradePrice %v", rsp, o.TradePrice)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gotoxu/log/core"
 	"gitlab.mybcdn.com/golang/blockcoin/apidb"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
@@ -17,7 +18,7 @@ type rawExecutedOrder struct {
 	ClientOrderID    string  `json:"clientOrderId"`
 	Price            string  `json:"price"`
 	OrigQty          string  `json:"origQty"`
-	ExecutedQuoteQty string  `json:"executedQuoteQty"`
+	ExecutedQuoteQty string  `json:"cummulativeQuoteQty"` // 累计成交金额，币安接口中的拼写即为cummulative
 	ExecutedQty      string  `json:"executedQty"`
 	Status           string  `json:"status"`
 	TimeInForce      string  `json:"timeInForce"`
@@ -45,10 +46,9 @@ func (as *apiService) InsertOrder(or global.InsertReq) (global.InsertRsp, error)
 		params["price"] = strconv.FormatFloat(or.Price, 'f', -1, 64)
 	}
 	params["quantity"] = strconv.FormatFloat(or.Num, 'f', -1, 64)
-	params["timestamp"] = strconv.FormatInt(time.Now().Unix()*1000, 10)
-	// if or.NewClientOrderID != "" {
-	// 	params["newClientOrderId"] = or.NewClientOrderID
-	// }
+	if or.ClientOrderID != "" {
+		params["newClientOrderId"] = or.ClientOrderID
+	}
 	// if or.StopPrice != 0 {
 	// 	params["stopPrice"] = strconv.FormatFloat(or.StopPrice, 'f', -1, 64)
 	// }
@@ -61,7 +61,18 @@ func (as *apiService) InsertOrder(or global.InsertReq) (global.InsertRsp, error)
 		ClientOrderID string  `json:"clientOrderId"`
 		TransactTime  float64 `json:"transactTime"`
 	}{}
-	err := as.request("POST", "api/v3/order", params, &rawOrder, true, true)
+	place := func() error {
+		params["timestamp"] = strconv.FormatInt(unixMillis(as.clock.Now()), 10)
+		return as.request("POST", "api/v3/order", params, &rawOrder, true, true)
+	}
+	// 只有带上客户端订单号时才能确认下单是否已经生效，才允许重试
+	var lookup func() (bool, error)
+	if or.ClientOrderID != "" {
+		lookup = func() (bool, error) {
+			return as.lookupClientOrder(params["symbol"], or.ClientOrderID, &rawOrder.OrderID)
+		}
+	}
+	err := as.config.RetryDoOrder("binance", place, lookup)
 	if err != nil {
 		return global.InsertRsp{}, err
 	}
@@ -82,6 +93,26 @@ func (as *apiService) InsertOrder(or global.InsertReq) (global.InsertRsp, error)
 	// }, nil
 }
 
+// lookupClientOrder 通过客户端订单号确认订单是否已经存在，存在时将交易所订单号写入orderID
+func (as *apiService) lookupClientOrder(symbol, clientOrderID string, orderID *int64) (bool, error) {
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	params["origClientOrderId"] = clientOrderID
+	params["timestamp"] = strconv.FormatInt(unixMillis(as.clock.Now()), 10)
+	params["recvWindow"] = strconv.FormatInt(recvWindow(time.Second*5), 10)
+	rawOrder := rawExecutedOrder{}
+	err := as.request("GET", "api/v3/order", params, &rawOrder, true, true)
+	if e, ok := err.(*Error); ok && e.Code == -2013 {
+		// Order does not exist.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	*orderID = int64(rawOrder.OrderID)
+	return true, nil
+}
+
 // func (as *apiService) OrderStatus(qor global.StatusReq) (global.StatusRsp, error) {
 // 	params := make(map[string]string)
 // 	params["symbol"] = strings.ToUpper(qor.Base + qor.Quote)
@@ -104,7 +135,7 @@ func (as *apiService) InsertOrder(or global.InsertReq) (global.InsertRsp, error)
 // 		return global.StatusRsp{}, err
 // 	}
 // 	m := global.StatusRsp{}
-// 	m.TradePrice = or.ExecutePrice
+// 	m.TradePrice = or.avgPrice()
 // 	m.TradeNum = or.ExecutedQty
 
 // 	if or.ExecutedQty != 0. || or.Status == StatusPartiallyFilled {
@@ -134,7 +165,7 @@ func (as *apiService) InsertOrder(or global.InsertReq) (global.InsertRsp, error)
 func (as *apiService) CancelOrder(cor global.CancelReq) error {
 	params := make(map[string]string)
 	params["symbol"] = strings.ToUpper(cor.Base + cor.Quote)
-	params["timestamp"] = strconv.FormatInt(unixMillis(time.Now()), 10)
+	params["timestamp"] = strconv.FormatInt(unixMillis(as.clock.Now()), 10)
 	if cor.OrderNo != "" {
 		params["orderId"] = cor.OrderNo
 	}
@@ -189,10 +220,50 @@ func (as *apiService) OpenOrders(oor OpenOrdersRequest) ([]*ExecutedOrder, error
 	return eoc, nil
 }
 
+// AllOrders 查询历史订单，结果按订单号升序排列
+func (as *apiService) AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error) {
+	params := make(map[string]string)
+	params["symbol"] = aor.Symbol
+	if aor.OrderID != 0 {
+		params["orderId"] = strconv.FormatInt(aor.OrderID, 10)
+	}
+	if !aor.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(unixMillis(aor.StartTime), 10)
+	}
+	if !aor.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(unixMillis(aor.EndTime), 10)
+	}
+	if aor.Limit != 0 {
+		params["limit"] = strconv.Itoa(aor.Limit)
+	}
+	ts := aor.Timestamp
+	if ts.IsZero() {
+		ts = as.clock.Now()
+	}
+	params["timestamp"] = strconv.FormatInt(unixMillis(ts), 10)
+	if aor.RecvWindow != 0 {
+		params["recvWindow"] = strconv.FormatInt(recvWindow(aor.RecvWindow), 10)
+	}
+	rawOrders := []*rawExecutedOrder{}
+	err := as.requestRetry("GET", "api/v3/allOrders", params, &rawOrders, true, true)
+	if err != nil {
+		return nil, err
+	}
+	var eoc []*ExecutedOrder
+	for _, rawOrder := range rawOrders {
+		eo, err := executedOrderFromRaw(rawOrder)
+		if err != nil {
+			return nil, err
+		}
+		eoc = append(eoc, eo)
+	}
+	return eoc, nil
+}
+
 func (as *apiService) OrderStatus(qor global.StatusReq) (global.StatusRsp, error) {
 	params := make(map[string]string)
 	params["symbol"] = strings.ToUpper(qor.Base + qor.Quote)
-	params["timestamp"] = strconv.FormatInt(unixMillis(time.Now()), 10)
+	params["timestamp"] = strconv.FormatInt(unixMillis(as.clock.Now()), 10)
 	params["orderId"] = qor.OrderNo
 	params["recvWindow"] = strconv.FormatInt(recvWindow(time.Second*5), 10)
 
@@ -201,7 +272,7 @@ func (as *apiService) OrderStatus(qor global.StatusReq) (global.StatusRsp, error
 	// }
 
 	rawOrders := []rawExecutedOrder{}
-	err := as.request("GET", "api/v3/allOrders", params, &rawOrders, true, true)
+	err := as.requestRetry("GET", "api/v3/allOrders", params, &rawOrders, true, true)
 	if err != nil {
 		return global.StatusRsp{}, err
 	}
@@ -219,7 +290,7 @@ func (as *apiService) OrderStatus(qor global.StatusReq) (global.StatusRsp, error
 	}
 	or := eoc[0]
 	m := global.StatusRsp{}
-	m.TradePrice = or.ExecutePrice
+	m.TradePrice = or.avgPrice()
 	m.TradeNum = or.ExecutedQty
 
 	if or.Status == StatusPartiallyFilled {
@@ -242,13 +313,13 @@ func (as *apiService) OrderStatus(qor global.StatusReq) (global.StatusRsp, error
 		m.Status = global.FAILED
 		m.StatusMsg = "订单超时"
 	}
-	fmt.Printf("binance order status %+v\n", or)
+	as.logger.Logf(core.Debug, "binance order status %+v", or)
 	return m, nil
 }
 
 func (as *apiService) GetFund(global.FundReq) ([]global.Fund, error) {
 	params := make(map[string]string)
-	params["timestamp"] = strconv.FormatInt(unixMillis(time.Now()), 10)
+	params["timestamp"] = strconv.FormatInt(unixMillis(as.clock.Now()), 10)
 	params["recvWindow"] = strconv.FormatInt(recvWindow(5*time.Second), 10)
 
 	rawAccount := struct {
@@ -265,7 +336,7 @@ func (as *apiService) GetFund(global.FundReq) ([]global.Fund, error) {
 			Locked string `json:"locked"`
 		}
 	}{}
-	err := as.request("GET", "api/v3/account", params, &rawAccount, true, true)
+	err := as.requestRetry("GET", "api/v3/account", params, &rawAccount, true, true)
 	if err != nil {
 		return nil, err
 	}
@@ -367,6 +438,7 @@ func (as *apiService) MyTrades(mtr MyTradesRequest) ([]*Trade, error) {
 	return tc, nil
 }
 
+// Withdraw 提现接口没有可供查询的客户端编号，无法确认失败的请求是否已经生效，因此从不重试
 func (as *apiService) Withdraw(wr WithdrawRequest) (*WithdrawResult, error) {
 	params := make(map[string]string)
 	params["asset"] = wr.Asset
@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net"
 	"net/http"
 	"net/url"
-	"strings"
+	"strconv"
 	"time"
 
+	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
+	"github.com/gotoxu/log/core"
 )
 
 // Service represents service layer for Binance API.
@@ -43,7 +45,9 @@ type Service interface {
 	// OpenOrders returns list of open orders.
 	OpenOrders(oor OpenOrdersRequest) ([]*ExecutedOrder, error)
 	// AllOrders returns list of all previous orders.
-	//AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error)
+	AllOrders(aor AllOrdersRequest) ([]*ExecutedOrder, error)
+	// OrderHistory 遍历一段时间内的历史订单
+	OrderHistory(sreq global.TradeSymbol, start, end time.Time) *OrderIterator
 
 	// GetFund returns account data.
 	GetFund(global.FundReq) ([]global.Fund, error)
@@ -69,8 +73,18 @@ type Service interface {
 	// 查询深度行情
 	GetDepth(global.TradeSymbol) (global.Depth, error)
 	KlineWebsocket(symbol string, intr Interval) (chan *KlineEvent, error)
+	// 有限档深度快照，levels可选5、10、20
+	SubPartialDepth(sreq global.TradeSymbol, levels int, speed UpdateSpeed) (chan global.Depth, error)
+	// 最优挂单
+	SubBookTicker(global.TradeSymbol) (chan global.Depth, error)
+	AggTradeWebsocket(symbol string) (chan *AggTradeEvent, error)
 	Ticker24Websocket() (chan *Ticker24, error)
 	UserDataWebsocket(listenKey string) (chan *AccountEvent, error)
+	// 取消订阅行情stream，如btcusdt@depth
+	UnsubscribeStream(streams ...string) error
+	// 托管的用户数据流，自动创建和续期listenKey
+	SubOrderUpdate() (chan global.OrderUpdate, error)
+	SubBalanceUpdate() (chan global.BalanceUpdate, error)
 }
 
 type apiService struct {
@@ -79,7 +93,64 @@ type apiService struct {
 	APISec string
 	Signer Signer
 	Ctx    context.Context
-	proxy  *url.URL
+	config config.Config
+	clock  *config.Clock
+	logger core.Logger
+	paths  apiPaths
+	stream *userStream
+	mux    *streamMux
+}
+
+// apiPaths 现货和合约中路径不同的公共接口
+type apiPaths struct {
+	time         string
+	exchangeInfo string
+	listenKey    string
+}
+
+var spotPaths = apiPaths{
+	time:         "api/v1/time",
+	exchangeInfo: "api/v1/exchangeInfo",
+	listenKey:    "api/v1/userDataStream",
+}
+
+// NewClient 使用config创建一个Service
+func NewClient(config *config.Config) Service {
+	cfg := defaultConfig()
+	if config != nil {
+		cfg.MergeInEnv(testnetConfig(), config)
+	}
+	return newAPIService(cfg, spotPaths)
+}
+
+// newAPIService 使用合并好的配置创建apiService，现货和合约共用
+func newAPIService(cfg *config.Config, paths apiPaths) *apiService {
+	cfg.ApplyDryRun("binance", dryRun)
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	scheme := "https://"
+	if !*cfg.UseSSL {
+		scheme = "http://"
+	}
+
+	as := &apiService{
+		URL:    scheme + *cfg.RESTHost,
+		APIKey: *cfg.APIKey,
+		APISec: *cfg.Secret,
+		Signer: &HmacSigner{
+			Key: []byte(*cfg.Secret),
+		},
+		Ctx:    ctx,
+		config: *cfg,
+		logger: cfg.ExchangeLogger("binance"),
+		paths:  paths,
+		stream: &userStream{},
+		mux:    newStreamMux(),
+	}
+	as.clock = cfg.StartClock(as.serverTime)
+	return as
 }
 
 // NewAPIService creates instance of Service.
@@ -90,76 +161,108 @@ func NewAPIService(ctx context.Context, url, apiKey, apiSec string, pxy *url.URL
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	cfg := defaultConfig()
+	cfg.MergeIn((&config.Config{}).WithContext(ctx).WithProxy(pxy))
 
 	return &apiService{
 		URL:    url,
 		APIKey: apiKey,
 		APISec: apiSec,
-		proxy:  pxy,
 		Signer: &HmacSigner{
 			Key: []byte(apiSec),
 		},
-		Ctx: ctx,
+		Ctx:    ctx,
+		config: *cfg,
+		logger: cfg.ExchangeLogger("binance"),
+		paths:  spotPaths,
+		stream: &userStream{},
+		mux:    newStreamMux(),
 	}
 }
 
-func (as *apiService) request(method string, path string, params map[string]string,
-	rsp interface{}, apiKey bool, sign bool) error {
-	transport := &http.Transport{
-		Dial: func(netw, addr string) (net.Conn, error) {
-			conn, err := net.DialTimeout(netw, addr, time.Second*5) //设置建立连接超时
-			if err != nil {
-				return nil, err
-			}
-			conn.SetDeadline(time.Now().Add(time.Second * 5)) //设置发送接受数据超时
-			return conn, nil
-		},
-		ResponseHeaderTimeout: time.Second * 5,
-	}
-	if as.proxy != nil {
-		transport.Proxy = http.ProxyURL(as.proxy)
+// serverTime 查询服务器时间
+func (as *apiService) serverTime() (time.Time, error) {
+	rawTime := struct {
+		ServerTime int64 `json:"serverTime"`
+	}{}
+	if err := as.request("GET", as.paths.time, nil, &rawTime, false, false); err != nil {
+		return time.Time{}, err
 	}
-	client := &http.Client{
-		Transport: transport,
+	return timeFromUnixTimestampFloat(float64(rawTime.ServerTime))
+}
+
+// credentials 返回请求使用的api key和签名器
+// 配置了CredentialProvider或Signer时每次重新获取，以支持密钥轮换和远程签名
+func (as *apiService) credentials() (string, Signer, error) {
+	if as.config.CredentialProvider == nil && as.config.Signer == nil {
+		return as.APIKey, as.Signer, nil
 	}
-	url := fmt.Sprintf("%s/%s", as.URL, path)
+	cred, s, err := as.config.Signing("binance", signer.HMACSHA256Hex)
+	return cred.APIKey, s, err
+}
+
+// requestRetry 按配置的重试策略执行幂等请求，每次重试前刷新签名用的timestamp
+func (as *apiService) requestRetry(method string, path string, params map[string]string,
+	rsp interface{}, apiKey bool, sign bool) error {
+	return as.config.RetryDo("binance", func() error {
+		if _, ok := params["timestamp"]; ok {
+			params["timestamp"] = strconv.FormatInt(unixMillis(as.clock.Now()), 10)
+		}
+		return as.request(method, path, params, rsp, apiKey, sign)
+	})
+}
+
+func (as *apiService) request(method string, path string, params map[string]string,
+	rsp interface{}, apiKey bool, sign bool) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { as.config.ObserveRequest("binance", endpoint, status, err, start) }()
+	url := fmt.Sprintf("%s/%s", as.baseURL(), path)
 	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
 		return err
 	}
-	req.WithContext(as.Ctx)
+	ctx, cancel := as.config.RequestContext()
+	defer cancel()
+	req = req.WithContext(ctx)
 
 	q := req.URL.Query()
 	for key, val := range params {
 		q.Add(key, val)
 	}
-	if apiKey {
-		req.Header.Add("X-MBX-APIKEY", as.APIKey)
-	}
-	if sign {
-		//log.Println("queryString", q.Encode())
-		q.Add("signature", as.Signer.Sign([]byte(q.Encode())))
-		//log.Println("signature", as.Signer.Sign([]byte(q.Encode())))
+	if apiKey || sign {
+		key, s, err := as.credentials()
+		if err != nil {
+			return err
+		}
+		if apiKey {
+			req.Header.Add("X-MBX-APIKEY", key)
+		}
+		if sign {
+			sig, err := s.Sign([]byte(q.Encode()))
+			if err != nil {
+				return err
+			}
+			q.Add("signature", sig)
+		}
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := client.Do(req)
+	resp, err := as.config.Do("binance", req)
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
 
 	defer resp.Body.Close()
 	textRes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return warpError(err, "unable to read response from allOrders.get")
 	}
-	if !strings.Contains(path, "api/v1/exchangeInfo") &&
-		!strings.Contains(path, "api/v1/klines") &&
-		!strings.Contains(path, "api/v3/account") &&
-		!strings.Contains(path, "api/v1/depth") {
-		fmt.Println("binance http msg:", string(textRes))
-	}
+	config.LogResponse(as.logger, req, start, textRes)
 
+	if he := (&global.HTTPError{StatusCode: resp.StatusCode}); he.Temporary() {
+		return he
+	}
 	if resp.StatusCode != 200 {
 		return as.handleError(textRes)
 	}
@@ -169,3 +272,18 @@ func (as *apiService) request(method string, path string, params map[string]stri
 
 	return nil
 }
+
+// baseURL 返回rest请求的地址，URL未被修改且设置了备用地址时使用当前选中的地址
+func (as *apiService) baseURL() string {
+	if as.config.RESTHosts == nil {
+		return as.URL
+	}
+	scheme := "https://"
+	if !*as.config.UseSSL {
+		scheme = "http://"
+	}
+	if as.URL != scheme+*as.config.RESTHost {
+		return as.URL
+	}
+	return scheme + as.config.RESTEndpoint()
+}
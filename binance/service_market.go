@@ -1,24 +1,21 @@
 package binance
 
 import (
-	"log"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 func (as *apiService) GetAllSymbol() ([]global.TradeSymbol, error) {
-	r := &struct {
-		Symbols []TradePair `json:"symbols"`
-	}{}
-	err := as.request("GET", "api/v1/exchangeInfo", nil, &r, false, false)
+	symbols, err := as.exchangeSymbols()
 	if err != nil {
 		return nil, err
 	}
 	rr := []global.TradeSymbol{}
-	for _, s := range r.Symbols {
+	for _, s := range symbols {
 		rr = append(rr, global.TradeSymbol{
 			Base:  s.Base,
 			Quote: s.Quote,
@@ -27,6 +24,18 @@ func (as *apiService) GetAllSymbol() ([]global.TradeSymbol, error) {
 	return rr, nil
 }
 
+// exchangeSymbols 查询交易所的所有交易对
+func (as *apiService) exchangeSymbols() ([]TradePair, error) {
+	r := &struct {
+		Symbols []TradePair `json:"symbols"`
+	}{}
+	err := as.request("GET", as.paths.exchangeInfo, nil, &r, false, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.Symbols, nil
+}
+
 // func (as *apiService) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 // 	params := make(map[string]string)
 // 	params["symbol"] = strings.ToUpper(sreq.Base + sreq.Quote)
@@ -110,9 +119,9 @@ func (as *apiService) GetDepth(sreq global.TradeSymbol) (global.Depth, error) {
 		Bids         [][]interface{} `json:"bids"`
 		Asks         [][]interface{} `json:"asks"`
 	}{}
-	err := as.request("GET", "api/v1/depth", params, &rawBook, false, false)
+	err := as.requestRetry("GET", "api/v1/depth", params, &rawBook, false, false)
 	if err != nil {
-		log.Printf("binance depth error : %+v\n", err)
+		as.logger.Logf(core.Warn, "binance depth error : %+v", err)
 		return global.Depth{}, err
 	}
 	extractOrder := func(rawPrice, rawQuantity interface{}) (*Order, error) {
@@ -227,7 +236,7 @@ func (as *apiService) GetKline(kr global.KlineReq) ([]global.Kline, error) {
 	// 	params["endTime"] = strconv.FormatInt(kr.EndTime, 10)
 	// }
 	rawKlines := [][]interface{}{}
-	err := as.request("GET", "api/v1/klines", params, &rawKlines, false, false)
+	err := as.requestRetry("GET", "api/v1/klines", params, &rawKlines, false, false)
 	if err != nil {
 		return nil, err
 	}
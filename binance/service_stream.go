@@ -0,0 +1,355 @@
+package binance
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
+)
+
+// 币安每个连接最多订阅1024个stream，每秒最多接收5条订阅消息
+const (
+	maxStreamsPerConn = 1024
+	subscribeInterval = 250 * time.Millisecond
+	subscribeBatch    = 200
+)
+
+// streamMux 通过combined stream(/stream)把行情stream复用到少量的连接上，按stream名称分发推送
+type streamMux struct {
+	once     sync.Once
+	mutex    sync.Mutex
+	shards   []*streamShard
+	handlers map[string][]func([]byte) // key为stream名称
+	shardOf  map[string]*streamShard
+	id       int64
+}
+
+// streamShard 一个combined stream连接
+type streamShard struct {
+	conn    *websocket.Conn // 重连期间为nil，受streamMux.mutex保护
+	streams []string        // 受streamMux.mutex保护
+
+	writeMu   sync.Mutex // 串行发送订阅消息并限制频率
+	lastWrite time.Time
+}
+
+func newStreamMux() *streamMux {
+	return &streamMux{
+		handlers: make(map[string][]func([]byte)),
+		shardOf:  make(map[string]*streamShard),
+	}
+}
+
+// subscribe 订阅stream，推送的data部分交给h处理
+// 已经订阅的stream不会重复订阅，所有连接都已满时建立新的连接
+// h在连接的读取goroutine中直接调用，阻塞会使同一连接上的所有stream停止推送，
+// 因此h向channel发送时不能阻塞，channel已满时丢弃并调用ObserveDrop
+// 发送SUBSCRIBE失败时撤销本次订阅并返回错误
+func (as *apiService) subscribe(stream string, h func([]byte)) error {
+	m := as.mux
+	m.once.Do(func() { go as.closeStreams() })
+	m.mutex.Lock()
+	if hs, ok := m.handlers[stream]; ok {
+		m.handlers[stream] = append(hs, h)
+		m.mutex.Unlock()
+		return nil
+	}
+	var s *streamShard
+	for _, v := range m.shards {
+		if len(v.streams) < maxStreamsPerConn {
+			s = v
+			break
+		}
+	}
+	if s == nil {
+		// 先占用新的连接再在锁外建立连接，避免阻塞其他订阅和推送的分发
+		s = &streamShard{streams: []string{stream}}
+		m.shards = append(m.shards, s)
+		m.handlers[stream] = []func([]byte){h}
+		m.shardOf[stream] = s
+		m.mutex.Unlock()
+		return as.dialShard(s, stream)
+	}
+	s.streams = append(s.streams, stream)
+	m.handlers[stream] = []func([]byte){h}
+	m.shardOf[stream] = s
+	conn := s.conn
+	m.mutex.Unlock()
+	if conn == nil {
+		// 重连后会订阅该连接上的所有stream
+		return nil
+	}
+	if err := as.sendMethod(s, conn, "SUBSCRIBE", []string{stream}); err != nil {
+		as.logger.Logln(core.Warn, "binance subscribe", stream, err)
+		m.mutex.Lock()
+		as.unregister(s, stream)
+		m.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// dialShard 为subscribe新占用的连接建立websocket，连接期间加入的stream在连接成功后一起订阅
+func (as *apiService) dialShard(s *streamShard, stream string) error {
+	m := as.mux
+	conn, _, err := as.config.DialWSS(as.combinedURL(stream), nil)
+	m.mutex.Lock()
+	if err != nil {
+		as.unregister(s, stream)
+		retry := len(s.streams) != 0
+		if !retry {
+			as.removeShard(s)
+		}
+		m.mutex.Unlock()
+		as.logger.Logln(core.Warn, "dial:", err)
+		if retry {
+			// 连接期间加入的其他stream由重连负责订阅
+			go as.reconnectStreams(s)
+		}
+		return err
+	}
+	if len(s.streams) == 0 {
+		// 连接期间已经全部取消订阅
+		as.removeShard(s)
+		m.mutex.Unlock()
+		conn.Close()
+		return nil
+	}
+	s.conn = conn
+	rest := make([]string, 0, len(s.streams))
+	for _, v := range s.streams {
+		if v != stream {
+			rest = append(rest, v)
+		}
+	}
+	m.mutex.Unlock()
+	go as.readStreams(s, conn)
+	if as.Ctx.Err() != nil {
+		// closeStreams已经执行过
+		conn.Close()
+		return nil
+	}
+	if len(rest) != 0 {
+		if err := as.sendMethod(s, conn, "SUBSCRIBE", rest); err != nil {
+			// 读取失败后会重连并重新订阅
+			as.logger.Logln(core.Warn, "binance subscribe", err)
+		}
+	}
+	return nil
+}
+
+// unregister 撤销订阅失败的stream，期间有其他调用方订阅了同一stream时保留，由重连负责订阅
+// 调用方需持有streamMux.mutex
+func (as *apiService) unregister(s *streamShard, stream string) {
+	m := as.mux
+	if len(m.handlers[stream]) != 1 || m.shardOf[stream] != s {
+		return
+	}
+	delete(m.handlers, stream)
+	delete(m.shardOf, stream)
+	for i, v := range s.streams {
+		if v == stream {
+			s.streams = append(s.streams[:i], s.streams[i+1:]...)
+			return
+		}
+	}
+}
+
+// UnsubscribeStream 取消订阅stream，如btcusdt@depth，对应的channel不再收到推送
+func (as *apiService) UnsubscribeStream(streams ...string) error {
+	m := as.mux
+	m.mutex.Lock()
+	groups := make(map[*streamShard][]string)
+	for _, stream := range streams {
+		s, ok := m.shardOf[stream]
+		if !ok {
+			continue
+		}
+		delete(m.handlers, stream)
+		delete(m.shardOf, stream)
+		for i, v := range s.streams {
+			if v == stream {
+				s.streams = append(s.streams[:i], s.streams[i+1:]...)
+				break
+			}
+		}
+		groups[s] = append(groups[s], stream)
+	}
+	var closing []*websocket.Conn
+	conns := make(map[*streamShard]*websocket.Conn)
+	for s := range groups {
+		if len(s.streams) != 0 {
+			conns[s] = s.conn
+			continue
+		}
+		// 没有stream的连接直接关闭
+		as.removeShard(s)
+		if s.conn != nil {
+			closing = append(closing, s.conn)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, conn := range closing {
+		conn.Close()
+	}
+	for s, conn := range conns {
+		if conn == nil {
+			continue
+		}
+		if err := as.sendMethod(s, conn, "UNSUBSCRIBE", groups[s]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeShard 调用方需持有streamMux.mutex
+func (as *apiService) removeShard(s *streamShard) {
+	m := as.mux
+	for i, v := range m.shards {
+		if v == s {
+			m.shards = append(m.shards[:i], m.shards[i+1:]...)
+			return
+		}
+	}
+}
+
+// sendMethod 发送SUBSCRIBE或UNSUBSCRIBE，两条消息之间至少间隔subscribeInterval
+func (as *apiService) sendMethod(s *streamShard, conn *websocket.Conn, method string, streams []string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	for i := 0; i < len(streams); i += subscribeBatch {
+		end := i + subscribeBatch
+		if end > len(streams) {
+			end = len(streams)
+		}
+		if d := time.Until(s.lastWrite.Add(subscribeInterval)); d > 0 {
+			time.Sleep(d)
+		}
+		req := struct {
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+			ID     int64    `json:"id"`
+		}{method, streams[i:end], atomic.AddInt64(&as.mux.id, 1)}
+		err := as.config.WriteJSONFrame("binance", conn, req)
+		s.lastWrite = time.Now()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (as *apiService) readStreams(s *streamShard, conn *websocket.Conn) {
+	m := as.mux
+	for {
+		_, message, err := as.config.ReadFrame("binance", conn)
+		if err != nil {
+			conn.Close()
+			m.mutex.Lock()
+			if s.conn == conn {
+				s.conn = nil
+			}
+			removed := len(s.streams) == 0
+			m.mutex.Unlock()
+			select {
+			case <-as.Ctx.Done():
+				return
+			default:
+			}
+			if removed {
+				return
+			}
+			as.logger.Logln(core.Warn, "wsRead ", err, "五秒后重连...")
+			as.config.ObserveReconnect("binance", "stream")
+			as.reconnectStreams(s)
+			return
+		}
+		r := struct {
+			Stream string          `json:"stream"`
+			Data   json.RawMessage `json:"data"`
+			Error  *Error          `json:"error"`
+		}{}
+		if err := json.Unmarshal(message, &r); err != nil {
+			as.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(message))
+			continue
+		}
+		if r.Error != nil {
+			as.logger.Logln(core.Warn, "binance stream error", r.Error)
+			continue
+		}
+		if r.Stream == "" {
+			// SUBSCRIBE、UNSUBSCRIBE的响应
+			continue
+		}
+		m.mutex.Lock()
+		hs := m.handlers[r.Stream]
+		m.mutex.Unlock()
+		// handler不能阻塞，见subscribe
+		for _, h := range hs {
+			h(r.Data)
+		}
+	}
+}
+
+// reconnectStreams 每五秒重试一次，连接成功后重新订阅该连接上的所有stream
+func (as *apiService) reconnectStreams(s *streamShard) {
+	m := as.mux
+	for {
+		select {
+		case <-as.Ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+		m.mutex.Lock()
+		if len(s.streams) == 0 {
+			m.mutex.Unlock()
+			return
+		}
+		first := s.streams[0]
+		m.mutex.Unlock()
+
+		conn, _, err := as.config.DialWSS(as.combinedURL(first), nil)
+		if err != nil {
+			continue
+		}
+		m.mutex.Lock()
+		s.conn = conn
+		rest := make([]string, 0, len(s.streams))
+		for _, stream := range s.streams {
+			if stream != first {
+				rest = append(rest, stream)
+			}
+		}
+		m.mutex.Unlock()
+		as.logger.Logln(core.Info, "reconnect success")
+		go as.readStreams(s, conn)
+		if err := as.sendMethod(s, conn, "SUBSCRIBE", rest); err != nil {
+			as.logger.Logln(core.Warn, "binance resubscribe", err)
+		}
+		return
+	}
+}
+
+// closeStreams Ctx结束时关闭所有连接
+func (as *apiService) closeStreams() {
+	<-as.Ctx.Done()
+	m := as.mux
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, s := range m.shards {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}
+}
+
+// combinedURL 返回combined stream的地址
+func (as *apiService) combinedURL(streams ...string) string {
+	return "wss://" + *as.config.WSSHost + "/stream?streams=" + strings.Join(streams, "/")
+}
@@ -0,0 +1,195 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
+)
+
+// UpdateSpeed stream的推送间隔，深度可选100ms、1000ms，合约标记价格可选1s、3s
+type UpdateSpeed string
+
+var (
+	Speed100ms  = UpdateSpeed("100ms")
+	Speed1000ms = UpdateSpeed("1000ms")
+	Speed1s     = UpdateSpeed("1s")
+	Speed3s     = UpdateSpeed("3s")
+)
+
+// SubPartialDepth 订阅有限档深度(<symbol>@depth<levels>)，每次推送都是完整的快照
+// levels可选5、10、20，speed为空时默认1000ms
+func (as *apiService) SubPartialDepth(sreq global.TradeSymbol, levels int, speed UpdateSpeed) (chan global.Depth, error) {
+	if levels != 5 && levels != 10 && levels != 20 {
+		return nil, fmt.Errorf("binance partial depth levels must be 5, 10 or 20, got %d", levels)
+	}
+	stream := fmt.Sprintf("%s@depth%d", strings.ToLower(sreq.Base+sreq.Quote), levels)
+	switch speed {
+	case "", Speed1000ms:
+	case Speed100ms:
+		stream += "@" + string(speed)
+	default:
+		return nil, fmt.Errorf("binance depth update speed must be 100ms or 1000ms, got %s", speed)
+	}
+	dech := make(chan global.Depth, 100)
+	err := as.subscribe(stream, func(message []byte) {
+		rawDepth := struct {
+			LastUpdateID int64      `json:"lastUpdateId"`
+			Bids         [][]string `json:"bids"`
+			Asks         [][]string `json:"asks"`
+		}{}
+		if err := json.Unmarshal(message, &rawDepth); err != nil {
+			as.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(message))
+			return
+		}
+		r := global.Depth{
+			Base:  sreq.Base,
+			Quote: sreq.Quote,
+			Asks:  depthPairs(rawDepth.Asks),
+			Bids:  depthPairs(rawDepth.Bids),
+		}
+		as.config.ObserveMessage("binance", stream)
+		select {
+		case dech <- r:
+		default:
+			as.config.ObserveDrop("binance", stream)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dech, nil
+}
+
+// SubBookTicker 订阅最优挂单(<symbol>@bookTicker)，推送为只有一档的深度
+func (as *apiService) SubBookTicker(sreq global.TradeSymbol) (chan global.Depth, error) {
+	stream := strings.ToLower(sreq.Base+sreq.Quote) + "@bookTicker"
+	dech := make(chan global.Depth, 100)
+	err := as.subscribe(stream, func(message []byte) {
+		rawBook := struct {
+			UpdateID int64  `json:"u"`
+			Symbol   string `json:"s"`
+			BidPrice string `json:"b"`
+			BidQty   string `json:"B"`
+			AskPrice string `json:"a"`
+			AskQty   string `json:"A"`
+		}{}
+		if err := json.Unmarshal(message, &rawBook); err != nil {
+			as.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(message))
+			return
+		}
+		r := global.Depth{
+			Base:  sreq.Base,
+			Quote: sreq.Quote,
+			Asks:  depthPairs([][]string{{rawBook.AskPrice, rawBook.AskQty}}),
+			Bids:  depthPairs([][]string{{rawBook.BidPrice, rawBook.BidQty}}),
+		}
+		as.config.ObserveMessage("binance", stream)
+		select {
+		case dech <- r:
+		default:
+			as.config.ObserveDrop("binance", stream)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dech, nil
+}
+
+// AggTradeWebsocket 订阅归集成交(<symbol>@aggTrade)
+func (as *apiService) AggTradeWebsocket(symbol string) (chan *AggTradeEvent, error) {
+	stream := strings.ToLower(symbol) + "@aggTrade"
+	aech := make(chan *AggTradeEvent, 100)
+	err := as.subscribe(stream, func(message []byte) {
+		ae, err := parseAggTrade(message)
+		if err != nil {
+			as.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(message))
+			return
+		}
+		as.config.ObserveMessage("binance", stream)
+		select {
+		case aech <- ae:
+		default:
+			as.config.ObserveDrop("binance", stream)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aech, nil
+}
+
+func parseAggTrade(message []byte) (*AggTradeEvent, error) {
+	rawAggTrade := struct {
+		Type         string  `json:"e"`
+		Time         float64 `json:"E"`
+		Symbol       string  `json:"s"`
+		TradeID      int     `json:"a"`
+		Price        string  `json:"p"`
+		Quantity     string  `json:"q"`
+		FirstTradeID int     `json:"f"`
+		LastTradeID  int     `json:"l"`
+		Timestamp    float64 `json:"T"`
+		IsMaker      bool    `json:"m"`
+		// 不使用，但必须声明，否则encoding/json会忽略大小写把它填入IsMaker
+		Ignore bool `json:"M"`
+	}{}
+	if err := json.Unmarshal(message, &rawAggTrade); err != nil {
+		return nil, err
+	}
+	t, err := timeFromUnixTimestampFloat(rawAggTrade.Time)
+	if err != nil {
+		return nil, err
+	}
+	price, err := floatFromString(rawAggTrade.Price)
+	if err != nil {
+		return nil, err
+	}
+	qty, err := floatFromString(rawAggTrade.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := timeFromUnixTimestampFloat(rawAggTrade.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return &AggTradeEvent{
+		WSEvent: WSEvent{
+			Type:   rawAggTrade.Type,
+			Time:   t,
+			Symbol: rawAggTrade.Symbol,
+		},
+		AggTrade: AggTrade{
+			ID:           rawAggTrade.TradeID,
+			Price:        price,
+			Quantity:     qty,
+			FirstTradeID: rawAggTrade.FirstTradeID,
+			LastTradeID:  rawAggTrade.LastTradeID,
+			Timestamp:    ts,
+			BuyerMaker:   rawAggTrade.IsMaker,
+		},
+	}, nil
+}
+
+// depthPairs 转换[价格, 数量]档位，跳过无法解析或数量为0的档位
+func depthPairs(levels [][]string) []global.DepthPair {
+	r := make([]global.DepthPair, 0, len(levels))
+	for _, l := range levels {
+		if len(l) < 2 {
+			continue
+		}
+		p, err := floatFromString(l[0])
+		if err != nil {
+			continue
+		}
+		q, err := floatFromString(l[1])
+		if err != nil || q == 0 {
+			continue
+		}
+		r = append(r, global.DepthPair{Price: p, Size: q})
+	}
+	return r
+}
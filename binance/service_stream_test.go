@@ -0,0 +1,82 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSubscribeDialOutsideLock(t *testing.T) {
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stream" {
+			http.NotFound(w, r)
+			return
+		}
+		close(dialing)
+		<-release
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				Method string   `json:"method"`
+				Params []string `json:"params"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			for _, stream := range req.Params {
+				conn.WriteJSON(map[string]interface{}{"stream": stream, "data": json.RawMessage(`{}`)})
+			}
+		}
+	}))
+	defer srv.Close()
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	defer unblock()
+	as := newTestClient(srv).(*apiService)
+
+	errc := make(chan error, 1)
+	go func() { errc <- as.subscribe("a@depth", func([]byte) {}) }()
+	<-dialing
+
+	// 第一个连接还在建立时，其他订阅不会被阻塞
+	got := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- as.subscribe("b@depth", func([]byte) {
+			select {
+			case got <- struct{}{}:
+			default:
+			}
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscribe blocked by a pending dial")
+	}
+
+	unblock()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream added during the dial was not subscribed")
+	}
+}
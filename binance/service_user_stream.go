@@ -1,11 +1,22 @@
 package binance
 
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
+)
+
 func (as *apiService) StartUserDataStream() (string, error) {
 	params := make(map[string]string)
 	rsp := struct {
 		ListenKey string `json:"listenKey"`
 	}{}
-	err := as.request("POST", "api/v1/userDataStream", params, &rsp, true, false)
+	err := as.request("POST", as.paths.listenKey, params, &rsp, true, false)
 	if err != nil {
 		return "", err
 	}
@@ -15,7 +26,7 @@ func (as *apiService) KeepAliveUserDataStream(listenKey string) error {
 	params := make(map[string]string)
 	params["listenKey"] = listenKey
 
-	err := as.request("PUT", "api/v1/userDataStream", params, nil, true, false)
+	err := as.request("PUT", as.paths.listenKey, params, nil, true, false)
 	if err != nil {
 		return err
 	}
@@ -25,9 +36,349 @@ func (as *apiService) CloseUserDataStream(listenKey string) error {
 	params := make(map[string]string)
 	params["listenKey"] = listenKey
 
-	err := as.request("DELETE", "api/v1/userDataStream", params, nil, true, false)
+	err := as.request("DELETE", as.paths.listenKey, params, nil, true, false)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// userStream 托管的用户数据流：自动创建listenKey，每30分钟续期一次，
+// listenKey过期或断线重连时重新创建
+type userStream struct {
+	connMu    sync.Mutex // 串行执行首次连接
+	started   bool       // 首次连接成功后为true，之后由userStreamReconnect负责重连，受connMu保护
+	mutex     sync.Mutex
+	sock      *websocket.Conn
+	listenKey string
+	symbols   map[string]global.TradeSymbol // 交易所的symbol到交易对的映射
+	orders    chan global.OrderUpdate       // 调用SubOrderUpdate后才创建
+	balance   chan global.BalanceUpdate     // 调用SubBalanceUpdate后才创建
+	positions chan *PositionUpdate          // 只有合约有持仓推送，调用SubPositionUpdate后才创建
+}
+
+// userEvent 用户数据流的推送，字段为executionReport和outboundAccountPosition的合集
+type userEvent struct {
+	Type          string `json:"e"`
+	Time          int64  `json:"E"`
+	Symbol        string `json:"s"`
+	ClientOrderID string `json:"c"`
+	OrigClientID  string `json:"C"` // 撤单时为原订单的客户端订单号
+	Side          string `json:"S"`
+	OrderType     string `json:"o"`
+	Qty           string `json:"q"`
+	QuoteQty      string `json:"Q"` // 按计价币金额下的市价单
+	Price         string `json:"p"`
+	ExecType      string `json:"x"`
+	Status        string `json:"X"`
+	OrderID       int64  `json:"i"`
+	LastQty       string `json:"l"`
+	LastPrice     string `json:"L"`
+	Commission    string `json:"n"`
+	CommissionAs  string `json:"N"`
+	TradeTime     int64  `json:"T"`
+	// 以下字段不使用，但必须声明，否则encoding/json会忽略大小写把它们填入上面的同名字段
+	TradeID    int64  `json:"t"`
+	Ignore     int64  `json:"I"`
+	CreateTime int64  `json:"O"`
+	StopPrice  string `json:"P"`
+	Balances   []struct {
+		Asset  string `json:"a"`
+		Free   string `json:"f"`
+		Locked string `json:"l"`
+	} `json:"B"`
+}
+
+// SubOrderUpdate 通过用户数据流订阅所有交易对的订单变动(executionReport)
+func (as *apiService) SubOrderUpdate() (chan global.OrderUpdate, error) {
+	s, err := as.userStreamStart()
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.orders == nil {
+		s.orders = make(chan global.OrderUpdate, 100)
+	}
+	return s.orders, nil
+}
+
+// SubBalanceUpdate 通过用户数据流订阅资金变动(outboundAccountPosition)
+func (as *apiService) SubBalanceUpdate() (chan global.BalanceUpdate, error) {
+	s, err := as.userStreamStart()
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.balance == nil {
+		s.balance = make(chan global.BalanceUpdate, 100)
+	}
+	return s.balance, nil
+}
+
+// pushOrder 推送订单变动，没有订阅时丢弃，通道已满时丢弃并计数，避免阻塞读取
+func (as *apiService) pushOrder(u global.OrderUpdate) {
+	s := as.stream
+	s.mutex.Lock()
+	ch := s.orders
+	s.mutex.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- u:
+	default:
+		as.config.ObserveDrop("binance", "userdata:orders")
+	}
+}
+
+// pushBalance 推送资金变动，规则同pushOrder
+func (as *apiService) pushBalance(u global.BalanceUpdate) {
+	s := as.stream
+	s.mutex.Lock()
+	ch := s.balance
+	s.mutex.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- u:
+	default:
+		as.config.ObserveDrop("binance", "userdata:balance")
+	}
+}
+
+// userStreamStart 建立用户数据流，已经建立时直接返回
+// 首次连接失败时返回错误，下次调用会重新尝试；之后断线由后台自动重连
+func (as *apiService) userStreamStart() (*userStream, error) {
+	s := as.stream
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.started {
+		return s, nil
+	}
+	if err := as.userStreamConnect(); err != nil {
+		return nil, err
+	}
+	s.started = true
+	go as.userStreamKeepAlive()
+	return s, nil
+}
+
+// userStreamConnect 创建新的listenKey并连接
+func (as *apiService) userStreamConnect() error {
+	s := as.stream
+	if err := as.loadSymbols(); err != nil {
+		return err
+	}
+	var key string
+	err := as.config.RetryDo("binance", func() error {
+		var e error
+		key, e = as.StartUserDataStream()
+		return e
+	})
+	if err != nil {
+		return err
+	}
+	conn, _, err := as.config.DialWSS(as.wsURL(key), nil)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	s.sock, s.listenKey = conn, key
+	s.mutex.Unlock()
+
+	go func() {
+		for {
+			_, msg, err := as.config.ReadFrame("binance", conn)
+			if err != nil {
+				s.mutex.Lock()
+				if s.sock == conn {
+					s.sock = nil
+				}
+				s.mutex.Unlock()
+				select {
+				case <-as.Ctx.Done():
+					return
+				default:
+				}
+				as.logger.Logf(core.Warn, "binance user stream < %s > 断开连接，五秒后重连...", err.Error())
+				as.config.ObserveReconnect("binance", "userdata")
+				go as.userStreamReconnect()
+				return
+			}
+			as.userStreamParse(conn, msg)
+		}
+	}()
+	return nil
+}
+
+func (as *apiService) userStreamReconnect() {
+	for {
+		select {
+		case <-as.Ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+		if err := as.userStreamConnect(); err == nil {
+			return
+		}
+	}
+}
+
+// userStreamKeepAlive 每30分钟续期一次listenKey，listenKey已经失效时断开连接以便重新创建
+func (as *apiService) userStreamKeepAlive() {
+	s := as.stream
+	ticker := time.NewTicker(30 * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-as.Ctx.Done():
+			s.mutex.Lock()
+			if s.sock != nil {
+				s.sock.Close()
+			}
+			s.mutex.Unlock()
+			return
+		case <-ticker.C:
+		}
+		s.mutex.Lock()
+		key, conn := s.listenKey, s.sock
+		s.mutex.Unlock()
+		if conn == nil {
+			continue
+		}
+		err := as.KeepAliveUserDataStream(key)
+		if e, ok := err.(*Error); ok && e.Code == -1125 {
+			// This listenKey does not exist.
+			as.logger.Logln(core.Warn, "binance listenKey 已失效，重新创建")
+			conn.Close()
+		} else if err != nil {
+			as.logger.Logln(core.Warn, "binance listenKey 续期失败", err)
+		}
+	}
+}
+
+// loadSymbols 加载symbol到交易对的映射，用于解析推送中的symbol
+func (as *apiService) loadSymbols() error {
+	s := as.stream
+	s.mutex.Lock()
+	loaded := s.symbols != nil
+	s.mutex.Unlock()
+	if loaded {
+		return nil
+	}
+	var all []TradePair
+	err := as.config.RetryDo("binance", func() error {
+		var e error
+		all, e = as.exchangeSymbols()
+		return e
+	})
 	if err != nil {
 		return err
 	}
+	m := make(map[string]global.TradeSymbol, len(all))
+	for _, p := range all {
+		m[p.Symbol] = global.TradeSymbol{Base: p.Base, Quote: p.Quote}
+	}
+	s.mutex.Lock()
+	s.symbols = m
+	s.mutex.Unlock()
 	return nil
 }
+
+func (as *apiService) userStreamParse(conn *websocket.Conn, msg []byte) {
+	s := as.stream
+	var head struct {
+		Type string `json:"e"`
+		Time int64  `json:"E"` // 必须声明，否则会被忽略大小写填入Type
+	}
+	if err := json.Unmarshal(msg, &head); err != nil {
+		as.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(msg))
+		return
+	}
+	if head.Type == "ORDER_TRADE_UPDATE" || head.Type == "ACCOUNT_UPDATE" {
+		as.futuresUserParse(msg)
+		return
+	}
+	var e userEvent
+	if err := json.Unmarshal(msg, &e); err != nil {
+		as.logger.Logln(core.Warn, "wsUnmarshal", err, "body", string(msg))
+		return
+	}
+	switch e.Type {
+	case "executionReport":
+		s.mutex.Lock()
+		key, ok := s.symbols[e.Symbol]
+		s.mutex.Unlock()
+		if !ok {
+			key = global.TradeSymbol{Base: e.Symbol}
+		}
+		as.config.ObserveMessage("binance", "userdata:executionReport")
+		as.pushOrder(e.toOrderUpdate(key))
+	case "outboundAccountPosition", "outboundAccountInfo":
+		as.config.ObserveMessage("binance", "userdata:"+e.Type)
+		for _, b := range e.Balances {
+			u := global.BalanceUpdate{Base: b.Asset, Timestamp: e.Time}
+			u.Available, _ = strconv.ParseFloat(b.Free, 64)
+			u.Frozen, _ = strconv.ParseFloat(b.Locked, 64)
+			as.pushBalance(u)
+		}
+	case "listenKeyExpired":
+		// 断开连接后重连时会创建新的listenKey
+		as.logger.Logln(core.Warn, "binance listenKey 已过期，重新创建")
+		conn.Close()
+	}
+	// balanceUpdate只有变动量，余额以随后的outboundAccountPosition为准
+}
+
+func (e *userEvent) toOrderUpdate(key global.TradeSymbol) global.OrderUpdate {
+	u := global.OrderUpdate{
+		Base:          key.Base,
+		Quote:         key.Quote,
+		OrderNo:       strconv.FormatInt(e.OrderID, 10),
+		ClientOrderID: e.ClientOrderID,
+		Status:        orderStatus(OrderStatus(e.Status)),
+		Timestamp:     e.Time,
+	}
+	if e.OrigClientID != "" {
+		u.ClientOrderID = e.OrigClientID
+	}
+	if OrderSide(e.Side) == SideSell {
+		u.Direction = 1
+	}
+	if OrderType(e.OrderType) == TypeMarket {
+		u.Type = 1
+	}
+	u.Price, _ = strconv.ParseFloat(e.Price, 64)
+	u.Num, _ = strconv.ParseFloat(e.Qty, 64)
+	if u.Num == 0 {
+		u.Num, _ = strconv.ParseFloat(e.QuoteQty, 64)
+	}
+	if e.ExecType == "TRADE" {
+		u.TradePrice, _ = strconv.ParseFloat(e.LastPrice, 64)
+		u.TradeNum, _ = strconv.ParseFloat(e.LastQty, 64)
+		u.Fee, _ = strconv.ParseFloat(e.Commission, 64)
+		u.FeeAsset = e.CommissionAs
+		u.Timestamp = e.TradeTime
+	}
+	return u
+}
+
+// orderStatus 将币安的订单状态转换为global中的状态
+func orderStatus(s OrderStatus) int {
+	switch s {
+	case StatusNew:
+		return global.HANGING
+	case StatusPartiallyFilled:
+		return global.HALFTRADE
+	case StatusFilled:
+		return global.COMPLETETRADE
+	case StatusPendingCancel:
+		return global.CANCELING
+	case StatusCancelled:
+		return global.CANCELED
+	}
+	return global.FAILED
+}
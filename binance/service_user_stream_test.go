@@ -0,0 +1,86 @@
+package binance
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/gorilla/websocket"
+)
+
+// testConfig 返回rest和websocket都连接到srv的配置
+func testConfig(srv *httptest.Server) *config.Config {
+	host := strings.TrimPrefix(srv.URL, "https://")
+	return (&config.Config{}).
+		WithRESTHost(host).
+		WithWSSHost(host).
+		WithAPIKey("key").
+		WithSecret("secret").
+		WithHTTPClient(srv.Client()).
+		WithWSSDialer(&websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}})
+}
+
+// newTestClient 返回连接到srv的现货Service
+func newTestClient(srv *httptest.Server) Service {
+	return NewClient(testConfig(srv))
+}
+
+func TestUserStreamRetryAfterFailedConnect(t *testing.T) {
+	var posts int32
+	push := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/exchangeInfo":
+			w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","baseAsset":"BTC","quoteAsset":"USDT"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/api/v1/userDataStream":
+			// 第一次创建listenKey失败
+			if atomic.AddInt32(&posts, 1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"code":-1001,"msg":"Internal error; unable to process your request."}`))
+				return
+			}
+			w.Write([]byte(`{"listenKey":"key1"}`))
+		case r.URL.Path == "/ws/key1":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			<-push
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"e":"executionReport","E":1,"s":"BTCUSDT",
+				"c":"c1","S":"BUY","o":"LIMIT","q":"1","p":"100","x":"NEW","X":"NEW","i":7}`))
+			conn.ReadMessage()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv)
+
+	if _, err := c.SubOrderUpdate(); err == nil {
+		t.Fatal("first connect should fail")
+	}
+	orders, err := c.SubOrderUpdate()
+	if err != nil {
+		t.Fatalf("second connect: %v", err)
+	}
+	if n := atomic.LoadInt32(&posts); n != 2 {
+		t.Fatalf("listenKey created %d times, want 2", n)
+	}
+	close(push)
+	select {
+	case u := <-orders:
+		if u.Base != "BTC" || u.Quote != "USDT" || u.OrderNo != "7" {
+			t.Errorf("unexpected update %+v", u)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order update")
+	}
+}
@@ -4,13 +4,12 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
 )
 
-// Signer signs provided payloads.
-type Signer interface {
-	// Sign signs provided payload and returns encoded string sum.
-	Sign(payload []byte) string
-}
+// Signer signs provided payloads, same as config.Signer.
+type Signer = config.Signer
 
 // HmacSigner uses HMAC SHA256 for signing payloads.
 type HmacSigner struct {
@@ -18,8 +17,8 @@ type HmacSigner struct {
 }
 
 // Sign signs provided payload and returns encoded string sum.
-func (hs *HmacSigner) Sign(payload []byte) string {
+func (hs *HmacSigner) Sign(payload []byte) (string, error) {
 	mac := hmac.New(sha256.New, hs.Key)
 	mac.Write(payload)
-	return hex.EncodeToString(mac.Sum(nil))
+	return hex.EncodeToString(mac.Sum(nil)), nil
 }
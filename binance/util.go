@@ -3,9 +3,10 @@ package binance
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 	"time"
+
+	"github.com/gotoxu/log/core"
 )
 
 func floatFromString(raw interface{}) (float64, error) {
@@ -62,7 +63,7 @@ func recvWindow(d time.Duration) int64 {
 
 func (as *apiService) handleError(textRes []byte) error {
 	err := &Error{}
-	log.Println("errorResponse:", string(textRes))
+	as.logger.Logln(core.Warn, "errorResponse:", string(textRes))
 	if err := json.Unmarshal(textRes, err); err != nil {
 		return warpError(err, "error unmarshal failed")
 	}
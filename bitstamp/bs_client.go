@@ -3,13 +3,14 @@ package bitstamp
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/baseclass"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
 	jsoniter "github.com/json-iterator/go"
 )
@@ -17,29 +18,43 @@ import (
 // Client 提供 API的调用客户端
 type Client struct {
 	baseclass.Client
+	nonce *config.Nonce
 }
 
 // NewClient 创建一个新的client
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(nil, config)
 	}
-	c := &Client{}
+	c := &Client{nonce: cfg.NewNonce(time.Second, nil)}
 	c.Exchange = "bitstamp"
-	c.Constructor(config)
+	c.Constructor(cfg)
+	c.Config.ApplyDryRun(c.Exchange, dryRun)
 	return c
 }
 
-func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.Config.ObserveRequest(c.Exchange, endpoint, status, err, start) }()
 	if in == nil {
 		in = make(map[string]interface{})
 	}
 	sig := ""
 	if bs {
-		in["key"] = *c.Config.APIKey
-		in["nonce"] = utils.ToString(time.Now().Unix())
-		sig = sign(utils.MapEncode(in), *c.Config.Secret)
+		cred, s, err := c.Config.Signing(c.Exchange, signer.HMACSHA256UpperHex)
+		if err != nil {
+			return err
+		}
+		in["key"] = cred.APIKey
+		nonce, err := c.nonce.Next()
+		if err != nil {
+			return err
+		}
+		in["nonce"] = utils.ToString(nonce)
+		if sig, err = s.Sign([]byte(utils.MapEncode(in))); err != nil {
+			return err
+		}
 		in["signature"] = sig
 	}
 	rbody, _ := json.Marshal(in)
@@ -48,25 +63,31 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	path += "?" + utils.MapEncode(in)
 
-	fmt.Println(path)
 	req, err := http.NewRequest(method, path, bytes.NewReader(rbody))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) "+
 		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
 
-	resp, err := c.Config.HTTPClient.Do(req)
+	ctx, cancel := c.Config.RequestContext()
+	defer cancel()
+	resp, err := c.Config.Do(c.Exchange, req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("http message: %s\n", string(body))
+	config.LogResponse(c.Logger, req, start, body)
 	//extra.RegisterFuzzyDecoders()
 
 	err = jsoniter.Unmarshal(body, out)
@@ -75,3 +96,10 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	return nil
 }
+
+// httpReqRetry 按配置的重试策略执行幂等的httpReq请求
+func (c *Client) httpReqRetry(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+	return c.Config.RetryDo(c.Exchange, func() error {
+		return c.httpReq(method, path, utils.CopyMap(in), out, bs)
+	})
+}
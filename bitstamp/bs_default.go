@@ -10,7 +10,7 @@ import (
 func defaultConfig() *config.Config {
 	cfg := &config.Config{}
 
-	cfg.WithRESTHost("")
+	cfg.WithRESTHost("www.bitstamp.net")
 	cfg.WithSecret("")
 	cfg.WithAPIKey("")
 	transport := clean.DefaultPooledTransport()
@@ -0,0 +1,23 @@
+package bitstamp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单和撤单的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	if req.Method != "POST" {
+		return nil, false
+	}
+	switch p := req.URL.Path; {
+	case strings.HasPrefix(p, "/api/v2/buy/"), strings.HasPrefix(p, "/api/v2/sell/"):
+		return []byte(fmt.Sprintf(`{"id":"%d","status":"dry run"}`, config.DryRunOrderID())), true
+	case p == "/api/v2/cancel_order/":
+		return []byte(`{"status":"dry run"}`), true
+	}
+	return nil, false
+}
@@ -8,7 +8,7 @@ import (
 // GetAllSymbol 交易市场详细行情接口
 func (c *Client) GetAllSymbol() ([]global.TradeSymbol, error) {
 	r := []map[string]interface{}{}
-	err := c.httpReq("GET", "https://www.bitstamp.net/api/v2/trading-pairs-info/", nil, &r, false)
+	err := c.httpReq("GET", c.Config.RESTURL("/api/v2/trading-pairs-info/"), nil, &r, false)
 	if err != nil {
 		return nil, err
 	}
@@ -13,7 +13,7 @@ import (
 func (c *Client) GetFund(global.FundReq) ([]global.Fund, error) {
 	in := map[string]interface{}{}
 	r := map[string]interface{}{}
-	err := c.httpReq("POST", "https://www.bitstamp.net/api/v2/balance/", in, &r, true)
+	err := c.httpReqRetry("POST", c.Config.RESTURL("/api/v2/balance/"), in, &r, true)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +25,7 @@ func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
 	in := map[string]interface{}{}
 	in["amount"] = req.Num
 
-	path := "https://www.bitstamp.net/api/v2/"
+	path := c.Config.RESTURL("/api/v2/")
 	if req.Direction == 0 {
 		path += "buy/"
 	} else {
@@ -56,7 +56,7 @@ func (c *Client) CancelOrder(req global.CancelReq) error {
 	in := map[string]interface{}{}
 	in["id"] = req.OrderNo
 	r := map[string]interface{}{}
-	err := c.httpReq("POST", "https://www.bitstamp.net/api/v2/cancel_order/", in, &r, true)
+	err := c.httpReq("POST", c.Config.RESTURL("/api/v2/cancel_order/"), in, &r, true)
 	if err != nil {
 		return err
 	}
@@ -70,7 +70,7 @@ func (c *Client) CancelOrder(req global.CancelReq) error {
 func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 	in := map[string]interface{}{}
 	r := map[string]interface{}{}
-	err := c.httpReq("POST", "https://www.bitstamp.net/api/order_status/", in, &r, true)
+	err := c.httpReqRetry("POST", c.Config.RESTURL("/api/order_status/"), in, &r, true)
 	if err != nil {
 		return global.StatusRsp{}, err
 	}
@@ -3,7 +3,6 @@ package coinegg
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"sync"
@@ -11,7 +10,9 @@ import (
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/gotoxu/log/core"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/json-iterator/go/extra"
 )
@@ -19,6 +20,9 @@ import (
 // Client 提供 API的调用客户端
 type Client struct {
 	config    config.Config
+	logger    core.Logger
+	clock     *config.Clock
+	nonce     *config.Nonce
 	mutex     sync.Mutex
 	tick      map[global.TradeSymbol]chan global.Ticker
 	depth     map[global.TradeSymbol]chan global.Depth
@@ -29,26 +33,42 @@ type Client struct {
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(nil, config)
 	}
 	extra.RegisterFuzzyDecoders()
+	clock := cfg.StartClock(cfg.HTTPDate(cfg.RESTURL("/")))
 	return &Client{
 		config:    *cfg,
+		logger:    cfg.ExchangeLogger("coinegg"),
+		clock:     clock,
+		nonce:     cfg.NewNonce(time.Second, clock),
 		tick:      make(map[global.TradeSymbol]chan global.Ticker),
 		depth:     make(map[global.TradeSymbol]chan global.Depth),
 		latetrade: make(map[global.TradeSymbol]chan global.LateTrade),
 	}
 }
 
-func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.config.ObserveRequest("coinegg", endpoint, status, err, start) }()
 	if in == nil {
 		in = make(map[string]interface{})
 	}
 	sig := ""
 	if bs {
-		in["key"] = *c.config.APIKey
-		in["nonce"] = utils.ToString(time.Now().Unix())
-		sig = sign(utils.MapEncode(in), *c.config.Secret)
+		cred, s, err := c.config.Signing("coinegg", signer.HMACSHA256MD5Key)
+		if err != nil {
+			return err
+		}
+		in["key"] = cred.APIKey
+		nonce, err := c.nonce.Next()
+		if err != nil {
+			return err
+		}
+		in["nonce"] = utils.ToString(nonce)
+		if sig, err = s.Sign([]byte(utils.MapEncode(in))); err != nil {
+			return err
+		}
 		in["signature"] = sig
 	}
 	rbody, _ := json.Marshal(in)
@@ -57,25 +77,31 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	path += "?" + utils.MapEncode(in)
 
-	fmt.Println(path)
 	req, err := http.NewRequest(method, path, bytes.NewReader(rbody))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) "+
 		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
 
-	resp, err := c.config.HTTPClient.Do(req)
+	ctx, cancel := c.config.RequestContext()
+	defer cancel()
+	resp, err := c.config.Do("coinegg", req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("http message: %s\n", string(body))
+	config.LogResponse(c.logger, req, start, body)
 	//extra.RegisterFuzzyDecoders()
 
 	err = jsoniter.Unmarshal(body, out)
@@ -84,3 +110,10 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	return nil
 }
+
+// httpReqRetry 按配置的重试策略执行幂等的httpReq请求
+func (c *Client) httpReqRetry(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+	return c.config.RetryDo("coinegg", func() error {
+		return c.httpReq(method, path, utils.CopyMap(in), out, bs)
+	})
+}
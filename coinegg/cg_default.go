@@ -10,6 +10,7 @@ import (
 func defaultConfig() *config.Config {
 	cfg := &config.Config{}
 
+	// RESTHost只用于交易接口，行情接口固定使用www.coinegg.com
 	cfg.WithRESTHost("api.coinegg.com")
 	cfg.WithSecret("")
 	cfg.WithAPIKey("")
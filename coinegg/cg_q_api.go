@@ -2,10 +2,10 @@ package coinegg
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/gotoxu/log/core"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 )
@@ -24,7 +24,7 @@ func (c *Client) GetAllSymbol() ([]global.TradeSymbol, error) {
 		path := fmt.Sprintf("https://www.coinegg.com/coin/%s/allcoin", q)
 		err := c.httpReq("GET", path, nil, &r, false)
 		if err != nil {
-			log.Printf("%s error: %s\n", path, err.Error())
+			c.logger.Logf(core.Warn, "%s error: %s", path, err.Error())
 			continue
 		}
 		for k := range r {
@@ -46,7 +46,7 @@ func (c *Client) GetDepth(req global.TradeSymbol) (global.Depth, error) {
 		Bids [][]interface{} `json:"buy"`
 		Asks [][]interface{} `json:"sell"`
 	}{}
-	err := c.httpReq("GET", strings.ToLower(path), nil, &r, false)
+	err := c.httpReqRetry("GET", strings.ToLower(path), nil, &r, false)
 	if err != nil {
 		return global.Depth{}, err
 	}
@@ -6,7 +6,7 @@ import "github.com/blockcdn-go/exchange-sdk-go/global"
 func (c *Client) GetFund(global.FundReq) ([]global.Fund, error) {
 	arg := map[string]interface{}{}
 	r := map[string]interface{}{}
-	err := c.httpReq("POST", "https://api.coinegg.com/api/v1/balance/", arg, &r, true)
+	err := c.httpReqRetry("POST", c.config.RESTURL("/api/v1/balance/"), arg, &r, true)
 	if err != nil {
 		return nil, err
 	}
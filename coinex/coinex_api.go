@@ -23,7 +23,7 @@ func (c *Client) getLateTrade(id interface{}, req global.TradeSymbol) ([]global.
 
 	data := []map[string]interface{}{}
 	r := plainRsp{Data: &data}
-	err := c.httpReq("GET", "https://api.coinex.com/v1/market/deals", in, &r, false)
+	err := c.httpReq("GET", c.Config.RESTURL("/v1/market/deals"), in, &r, false)
 	if err != nil {
 		return nil, err
 	}
@@ -3,12 +3,12 @@ package coinex
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
 	"github.com/gorilla/websocket"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
@@ -23,6 +23,8 @@ import (
 type Client struct {
 	baseclass.Client
 	sock     *websocket.Conn
+	clock    *config.Clock
+	nonce    *config.Nonce
 	tickOnce sync.Once
 	mtx      sync.Mutex
 	ltid     int64 // 最后一次成交的id
@@ -33,25 +35,40 @@ type Client struct {
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(nil, config)
 	}
 	c := &Client{
 		tick: make(map[global.TradeSymbol]chan global.Ticker),
 	}
 	c.Exchange = "coinex"
-	c.Constructor(config)
+	c.Constructor(cfg)
+	c.Config.ApplyDryRun(c.Exchange, dryRun)
+	c.clock = cfg.StartClock(cfg.HTTPDate(cfg.RESTURL("/")))
+	c.nonce = cfg.NewNonce(time.Millisecond, c.clock)
 	return c
 }
 
-func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.Config.ObserveRequest(c.Exchange, endpoint, status, err, start) }()
 	if in == nil {
 		in = make(map[string]interface{})
 	}
 	sig := ""
 	if bs {
-		in["access_id"] = *c.Config.APIKey
-		in["tonce"] = utils.ToString(time.Now().UnixNano() / 1000000)
-		sig = sign(utils.MapEncode(in)+"&secret_key="+*c.Config.Secret, *c.Config.Secret)
+		cred, s, err := c.Config.Signing(c.Exchange, signer.MD5WithSecret)
+		if err != nil {
+			return err
+		}
+		in["access_id"] = cred.APIKey
+		tonce, err := c.nonce.Next()
+		if err != nil {
+			return err
+		}
+		in["tonce"] = utils.ToString(tonce)
+		if sig, err = s.Sign([]byte(utils.MapEncode(in))); err != nil {
+			return err
+		}
 	}
 	rbody, _ := json.Marshal(in)
 	if method == "GET" {
@@ -59,27 +76,33 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	path += "?" + utils.MapEncode(in)
 
-	fmt.Println(path)
 	req, err := http.NewRequest(method, path, bytes.NewReader(rbody))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) "+
 		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
 	if bs {
 		req.Header.Set("authorization", sig)
 	}
-	resp, err := c.Config.HTTPClient.Do(req)
+	ctx, cancel := c.Config.RequestContext()
+	defer cancel()
+	resp, err := c.Config.Do(c.Exchange, req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("http message: %s\n", string(body))
+	config.LogResponse(c.Logger, req, start, body)
 	//extra.RegisterFuzzyDecoders()
 
 	err = jsoniter.Unmarshal(body, out)
@@ -88,3 +111,10 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	return nil
 }
+
+// httpReqRetry 按配置的重试策略执行幂等的httpReq请求
+func (c *Client) httpReqRetry(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+	return c.Config.RetryDo(c.Exchange, func() error {
+		return c.httpReq(method, path, utils.CopyMap(in), out, bs)
+	})
+}
@@ -5,18 +5,20 @@ import (
 
 	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/gorilla/websocket"
 )
 
 func defaultConfig() *config.Config {
 	cfg := &config.Config{}
 
-	cfg.WithRESTHost("")
+	cfg.WithRESTHost("api.coinex.com")
 	cfg.WithSecret("")
 	cfg.WithAPIKey("")
 	transport := clean.DefaultPooledTransport()
 	// u, _ := url.Parse("http://127.0.0.1:8118")
 	// transport.Proxy = http.ProxyURL(u)
 	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithWSSDialer(websocket.DefaultDialer)
 	cfg.WithUseSSL(true)
 
 	return cfg
@@ -0,0 +1,22 @@
+package coinex
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单和撤单的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	if req.Method != "POST" {
+		return nil, false
+	}
+	switch req.URL.Path {
+	case "/v1/order/limit", "/v1/order/market":
+		return []byte(fmt.Sprintf(`{"code":0,"message":"dry run","data":{"id":%d}}`, config.DryRunOrderID())), true
+	case "/v1/order/pending":
+		return []byte(`{"code":0,"message":"dry run","data":{}}`), true
+	}
+	return nil, false
+}
@@ -51,7 +51,12 @@ func (c *Client) parse(msg []byte) {
 					ret.PriceChange = ret.LastPrice - open
 					ret.PriceChangePercent = ret.PriceChange / ret.LastPrice * 100
 				}
-				ch <- ret
+				c.Config.ObserveMessage("coinex", "ticker:"+key.Base+key.Quote)
+				select {
+				case ch <- ret:
+				default:
+					c.Config.ObserveDrop("coinex", "ticker:"+key.Base+key.Quote)
+				}
 			}
 
 		}
@@ -14,7 +14,7 @@ func (c *Client) GetAllSymbol() ([]global.TradeSymbol, error) {
 	data := []string{}
 	r := plainRsp{Data: &data}
 
-	err := c.httpReq("GET", "https://api.coinex.com/v1/market/list", nil, &r, false)
+	err := c.httpReq("GET", c.Config.RESTURL("/v1/market/list"), nil, &r, false)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +39,7 @@ func (c *Client) GetDepth(req global.TradeSymbol) (global.Depth, error) {
 	in := map[string]interface{}{}
 	in["market"] = strings.ToUpper(req.Base + req.Quote)
 	in["merge"] = 0
-	err := c.httpReq("GET", "https://api.coinex.com/v1/market/depth", in, &r, false)
+	err := c.httpReqRetry("GET", c.Config.RESTURL("/v1/market/depth"), in, &r, false)
 	if err != nil {
 		return global.Depth{}, err
 	}
@@ -70,7 +70,7 @@ func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
 	in["type"] = utils.Period2Suffix(req.Period, false)
 	data := [][]interface{}{}
 	r := plainRsp{Data: &data}
-	err := c.httpReq("GET", "https://api.coinex.com/v1/market/kline", in, &r, false)
+	err := c.httpReqRetry("GET", c.Config.RESTURL("/v1/market/kline"), in, &r, false)
 	if err != nil {
 		return nil, err
 	}
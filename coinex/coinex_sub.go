@@ -2,12 +2,11 @@ package coinex
 
 import (
 	"errors"
-	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
@@ -38,7 +37,12 @@ func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 			case <-time.After(10 * time.Second):
 				d, err := c.GetDepth(sreq)
 				if err != nil {
-					ch <- d
+					c.Config.ObserveMessage("coinex", "depth:"+sreq.Base+sreq.Quote)
+					select {
+					case ch <- d:
+					default:
+						c.Config.ObserveDrop("coinex", "depth:"+sreq.Base+sreq.Quote)
+					}
 				}
 			}
 		}
@@ -56,7 +60,12 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 			case <-time.After(10 * time.Second):
 				d, _ := c.getLateTrade(c.ltid, sreq)
 				for _, l := range d {
-					ch <- l
+					c.Config.ObserveMessage("coinex", "trade:"+sreq.Base+sreq.Quote)
+					select {
+					case ch <- l:
+					default:
+						c.Config.ObserveDrop("coinex", "trade:"+sreq.Base+sreq.Quote)
+					}
 				}
 			}
 		}
@@ -65,16 +74,16 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 }
 
 func (c *Client) connect(wsaddr string) (*websocket.Conn, error) {
-	log.Printf("coinex 连接 %s 中... ", wsaddr)
-	conn, _, err := c.Config.WSSDialer.Dial(wsaddr, nil)
-	log.Printf("连接: %s\n", utils.Ternary(err == nil, "成功", "失败").(string))
+	c.Logger.Logf(core.Info, "coinex 连接 %s 中... ", wsaddr)
+	conn, _, err := c.Config.DialWSS(wsaddr, nil)
+	c.Logger.Logf(core.Info, "连接: %s", utils.Ternary(err == nil, "成功", "失败").(string))
 	return conn, err
 }
 
 func (c *Client) wsConnect(wsaddr string) {
 	sock, err := c.connect(wsaddr)
 	if err != nil {
-		log.Printf("coinex connect failed %+v\n", err)
+		c.Logger.Logf(core.Warn, "coinex connect failed %+v", err)
 		c.sock = nil
 		return
 	}
@@ -84,7 +93,7 @@ func (c *Client) wsConnect(wsaddr string) {
 	req["id"] = time.Now().Unix()
 	req["method"] = "state.subscribe"
 	req["params"] = []interface{}{}
-	c.sock.WriteJSON(req)
+	c.Config.WriteJSONFrame("coinex", c.sock, req)
 
 	go func() {
 		ping := time.NewTicker(time.Second * 10)
@@ -96,7 +105,8 @@ func (c *Client) wsConnect(wsaddr string) {
 			default:
 				msg, err := c.readWSMessage(c.sock)
 				if err != nil {
-					log.Printf("coinex < %s > 断开连接，五秒后重连...\n", err.Error())
+					c.Logger.Logf(core.Warn, "coinex < %s > 断开连接，五秒后重连...", err.Error())
+					c.Config.ObserveReconnect("coinex", "market")
 					go func() {
 						time.Sleep(5 * time.Second)
 						c.wsConnect(wsaddr)
@@ -109,14 +119,14 @@ func (c *Client) wsConnect(wsaddr string) {
 
 				// 业务逻辑处理
 				c.parse(msg)
-				fmt.Println(string(msg))
+				c.Logger.Logln(core.Debug, string(msg))
 			}
 		}
 	}()
 }
 
 func (c *Client) readWSMessage(conn *websocket.Conn) ([]byte, error) {
-	_, msg, err := conn.ReadMessage()
+	_, msg, err := c.Config.ReadFrame("coinex", conn)
 	if err != nil {
 		return nil, err
 	}
@@ -14,7 +14,7 @@ func (c *Client) GetFund(global.FundReq) ([]global.Fund, error) {
 	in := map[string]interface{}{}
 	data := map[string]map[string]interface{}{}
 	r := plainRsp{Data: &data}
-	err := c.httpReq("GET", "https://api.coinex.com/v1/balance/", in, &r, true)
+	err := c.httpReqRetry("GET", c.Config.RESTURL("/v1/balance/"), in, &r, true)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +35,7 @@ func (c *Client) GetFund(global.FundReq) ([]global.Fund, error) {
 
 // InsertOrder 下单接口
 func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
-	path := "https://api.coinex.com/v1/order/"
+	path := c.Config.RESTURL("/v1/order/")
 	in := map[string]interface{}{}
 	data := map[string]interface{}{}
 	r := plainRsp{Data: &data}
@@ -65,7 +65,7 @@ func (c *Client) CancelOrder(req global.CancelReq) error {
 	r := plainRsp{Data: &data}
 	in["market"] = strings.ToUpper(req.Base + req.Quote)
 	in["id"] = int(utils.ToFloat(req.OrderNo))
-	err := c.httpReq("POST", "https://api.coinex.com/v1/order/pending", in, &r, true)
+	err := c.httpReq("POST", c.Config.RESTURL("/v1/order/pending"), in, &r, true)
 	if err != nil {
 		return err
 	}
@@ -82,7 +82,7 @@ func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 	r := plainRsp{Data: &data}
 	in["market"] = strings.ToUpper(req.Base + req.Quote)
 	in["id"] = int(utils.ToFloat(req.OrderNo))
-	err := c.httpReq("POST", "https://api.coinex.com/v1/order/", in, &r, true)
+	err := c.httpReqRetry("POST", c.Config.RESTURL("/v1/order/"), in, &r, true)
 	if err != nil {
 		return global.StatusRsp{}, err
 	}
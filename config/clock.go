@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Clock 维护本地时钟与交易所服务器时钟的偏移，用于签名时间戳和nonce
+// nil的Clock等价于直接使用本地时间
+type Clock struct {
+	offset int64 // 服务器时间减去本地时间，单位纳秒
+}
+
+// NewClock 创建一个偏移为0的Clock
+func NewClock() *Clock {
+	return &Clock{}
+}
+
+// Now 返回校准后的当前时间
+func (c *Clock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return time.Now().Add(c.Offset())
+}
+
+// Offset 返回当前记录的时钟偏移
+func (c *Clock) Offset() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&c.offset))
+}
+
+// Sync 调用fetch查询一次服务器时间并更新偏移
+// 以请求往返的中点作为服务器时间对应的本地时间
+func (c *Clock) Sync(fetch func() (time.Time, error)) error {
+	start := time.Now()
+	server, err := fetch()
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+	local := start.Add(end.Sub(start) / 2)
+	atomic.StoreInt64(&c.offset, int64(server.Sub(local)))
+	return nil
+}
+
+// Start 立即同步一次，之后每隔interval同步一次，直到ctx结束
+// 同步失败时保留上一次的偏移
+func (c *Clock) Start(ctx context.Context, interval time.Duration, fetch func() (time.Time, error)) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.Sync(fetch)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Sync(fetch)
+			}
+		}
+	}()
+}
+
+// HTTPDate 返回一个通过响应头Date获取服务器时间的fetch函数，精度为秒
+// 用于没有提供服务器时间接口的交易所
+func (c *Config) HTTPDate(url string) func() (time.Time, error) {
+	return func() (time.Time, error) {
+		resp, err := c.HTTPClient.Head(url)
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp.Body.Close()
+		return http.ParseTime(resp.Header.Get("Date"))
+	}
+}
+
+// StartClock 根据TimeSync配置创建并启动Clock，未开启时返回nil
+func (c *Config) StartClock(fetch func() (time.Time, error)) *Clock {
+	if c.TimeSync == nil || *c.TimeSync <= 0 {
+		return nil
+	}
+	clock := NewClock()
+	clock.Start(c.Context, *c.TimeSync, fetch)
+	return clock
+}
@@ -3,9 +3,12 @@ package config
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
 )
 
 // Config 是sdk的相关配置
@@ -14,11 +17,34 @@ type Config struct {
 	WSSHost      *string
 	APIKey       *string
 	Secret       *string
+	Passphrase   *string
 	UseSSL       *bool
 	PingDuration *time.Duration
 	HTTPClient   *http.Client
 	WSSDialer    *websocket.Dialer
 	Context      context.Context
+	Retry        *RetryPolicy
+	Timeout      *time.Duration
+	Proxy        *url.URL
+	TimeSync     *time.Duration
+	NonceFile    *string
+	Logger       core.Logger
+	Metrics      Metrics
+	Middlewares  []Middleware
+	FrameHooks   []FrameHook
+
+	CredentialProvider CredentialProvider
+	Signer             Signer
+	Environment        *Environment
+	DryRun             *bool
+	RESTHosts          *Endpoints
+	WSSHosts           *Endpoints
+	HealthCheck        *time.Duration
+	Account            *string
+	DepthStep          *string
+	DepthLevels        *int
+
+	noTestnet bool
 }
 
 // WithAPIKey 设置sdk访问的API key
@@ -33,6 +59,12 @@ func (c *Config) WithSecret(secret string) *Config {
 	return c
 }
 
+// WithPassphrase 设置API key的密码，okex等交易所签名时需要
+func (c *Config) WithPassphrase(passphrase string) *Config {
+	c.Passphrase = &passphrase
+	return c
+}
+
 // WithUseSSL 设置sdk访问rest接口时是否使用https
 func (c *Config) WithUseSSL(use bool) *Config {
 	c.UseSSL = &use
@@ -57,6 +89,26 @@ func (c *Config) WithContext(ctx context.Context) *Config {
 	return c
 }
 
+// WithAccount 设置下单和查询资金使用的账户，
+// 对于区分账户的交易所(如huobi)可以是账户类型(spot、margin等)或账户id
+func (c *Config) WithAccount(account string) *Config {
+	c.Account = &account
+	return c
+}
+
+// WithDepthStep 设置深度行情的合并档位，如huobi的step0(不合并)到step5
+func (c *Config) WithDepthStep(step string) *Config {
+	c.DepthStep = &step
+	return c
+}
+
+// WithDepthLevels 设置增量深度维护的档数，如huobi支持5、20、150，
+// 默认为0，使用全量深度推送
+func (c *Config) WithDepthLevels(levels int) *Config {
+	c.DepthLevels = &levels
+	return c
+}
+
 // WithRESTHost 设置rest接口的地址
 func (c *Config) WithRESTHost(host string) *Config {
 	c.RESTHost = &host
@@ -75,11 +127,112 @@ func (c *Config) WithPingDuration(dur time.Duration) *Config {
 	return c
 }
 
+// WithRetryPolicy 设置rest请求的重试策略
+func (c *Config) WithRetryPolicy(p *RetryPolicy) *Config {
+	c.Retry = p
+	return c
+}
+
+// WithTimeout 设置单次rest请求的超时时间，0表示只受Context控制
+func (c *Config) WithTimeout(dur time.Duration) *Config {
+	c.Timeout = &dur
+	return c
+}
+
+// RequestContext 返回单次rest请求使用的context
+// 以Context为基础，设置了Timeout时附加超时，调用方需在读取完响应后调用cancel
+func (c *Config) RequestContext() (context.Context, context.CancelFunc) {
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Timeout != nil && *c.Timeout > 0 {
+		return context.WithTimeout(ctx, *c.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// WithProxy 设置rest和websocket连接使用的代理，支持http、https和socks5
+// 代理只作用于当前配置合并出的client和dialer，不会修改全局的默认值
+// HTTPClient使用自定义的RoundTripper(非*http.Transport)时不会设置代理，需自行处理
+func (c *Config) WithProxy(proxy *url.URL) *Config {
+	c.Proxy = proxy
+	return c
+}
+
+// WithTimeSync 开启服务器时间同步并设置同步间隔，签名时间戳和nonce将使用校准后的时间
+func (c *Config) WithTimeSync(interval time.Duration) *Config {
+	c.TimeSync = &interval
+	return c
+}
+
+// WithNonceFile 设置nonce持久化文件，重启后nonce从文件记录的值继续递增
+// 每个client应使用单独的文件
+func (c *Config) WithNonceFile(path string) *Config {
+	c.NonceFile = &path
+	return c
+}
+
+// WithLogger 设置sdk使用的日志器，未设置时不输出日志
+func (c *Config) WithLogger(logger core.Logger) *Config {
+	c.Logger = logger
+	return c
+}
+
+// WithMetrics 设置sdk上报运行指标的实现
+func (c *Config) WithMetrics(m Metrics) *Config {
+	c.Metrics = m
+	return c
+}
+
+// WithMiddleware 追加rest请求的中间件
+func (c *Config) WithMiddleware(m ...Middleware) *Config {
+	c.Middlewares = append(c.Middlewares, m...)
+	return c
+}
+
+// WithFrameHook 追加websocket原始帧的回调
+func (c *Config) WithFrameHook(h ...FrameHook) *Config {
+	c.FrameHooks = append(c.FrameHooks, h...)
+	return c
+}
+
 // MergeIn 用于合并多个配置
 func (c *Config) MergeIn(cfgs ...*Config) {
 	for _, other := range cfgs {
 		mergeInConfig(c, other)
 	}
+	c.applyProxy()
+}
+
+// applyProxy 为HTTPClient和WSSDialer生成带代理的副本
+func (c *Config) applyProxy() {
+	if c.Proxy == nil {
+		return
+	}
+	proxy := http.ProxyURL(c.Proxy)
+
+	if c.HTTPClient != nil {
+		// 复制原有的transport，保留自定义的dialer、超时和连接池设置
+		var transport *http.Transport
+		switch t := c.HTTPClient.Transport.(type) {
+		case nil:
+			transport = clean.DefaultPooledTransport()
+		case *http.Transport:
+			transport = t.Clone()
+		}
+		if transport != nil {
+			transport.Proxy = proxy
+			client := *c.HTTPClient
+			client.Transport = transport
+			c.HTTPClient = &client
+		}
+	}
+	if c.WSSDialer != nil {
+		dialer := *c.WSSDialer
+		dialer.Proxy = proxy
+		c.WSSDialer = &dialer
+	}
 }
 
 func mergeInConfig(dst *Config, other *Config) {
@@ -96,11 +249,29 @@ func mergeInConfig(dst *Config, other *Config) {
 	if other.Secret != nil {
 		dst.Secret = other.Secret
 	}
+	if other.Passphrase != nil {
+		dst.Passphrase = other.Passphrase
+	}
 	if other.RESTHost != nil {
+		// 只设置了单个地址时不再使用之前的备用地址
 		dst.RESTHost = other.RESTHost
+		dst.RESTHosts = other.RESTHosts
 	}
 	if other.WSSHost != nil {
 		dst.WSSHost = other.WSSHost
+		dst.WSSHosts = other.WSSHosts
+	}
+	if other.Account != nil {
+		dst.Account = other.Account
+	}
+	if other.DepthStep != nil {
+		dst.DepthStep = other.DepthStep
+	}
+	if other.DepthLevels != nil {
+		dst.DepthLevels = other.DepthLevels
+	}
+	if other.HealthCheck != nil {
+		dst.HealthCheck = other.HealthCheck
 	}
 	if other.HTTPClient != nil {
 		dst.HTTPClient = other.HTTPClient
@@ -114,4 +285,43 @@ func mergeInConfig(dst *Config, other *Config) {
 	if other.PingDuration != nil {
 		dst.PingDuration = other.PingDuration
 	}
+	if other.Retry != nil {
+		dst.Retry = other.Retry
+	}
+	if other.Timeout != nil {
+		dst.Timeout = other.Timeout
+	}
+	if other.Proxy != nil {
+		dst.Proxy = other.Proxy
+	}
+	if other.TimeSync != nil {
+		dst.TimeSync = other.TimeSync
+	}
+	if other.NonceFile != nil {
+		dst.NonceFile = other.NonceFile
+	}
+	if other.Logger != nil {
+		dst.Logger = other.Logger
+	}
+	if other.Metrics != nil {
+		dst.Metrics = other.Metrics
+	}
+	if other.CredentialProvider != nil {
+		dst.CredentialProvider = other.CredentialProvider
+	}
+	if other.Environment != nil {
+		dst.Environment = other.Environment
+	}
+	if other.DryRun != nil {
+		dst.DryRun = other.DryRun
+	}
+	if other.Signer != nil {
+		dst.Signer = other.Signer
+	}
+	if len(other.Middlewares) > 0 {
+		dst.Middlewares = append(dst.Middlewares[:len(dst.Middlewares):len(dst.Middlewares)], other.Middlewares...)
+	}
+	if len(other.FrameHooks) > 0 {
+		dst.FrameHooks = append(dst.FrameHooks[:len(dst.FrameHooks):len(dst.FrameHooks)], other.FrameHooks...)
+	}
 }
@@ -0,0 +1,48 @@
+package config
+
+import "errors"
+
+// Credentials 一组交易所访问凭证
+type Credentials struct {
+	APIKey     string `json:"apikey"`
+	Secret     string `json:"secret"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// CredentialProvider 提供交易所的访问凭证
+// client在每次签名前调用Credentials，provider返回新的值即可完成密钥轮换，无需重建client
+type CredentialProvider interface {
+	Credentials(exchange string) (Credentials, error)
+}
+
+// ErrNoCredentials 表示没有配置访问凭证
+var ErrNoCredentials = errors.New("no credentials configured")
+
+// WithCredentialProvider 设置访问凭证的来源，设置后优先于APIKey和Secret
+func (c *Config) WithCredentialProvider(p CredentialProvider) *Config {
+	c.CredentialProvider = p
+	return c
+}
+
+// GetCredentials 返回exchange当前的访问凭证
+// 设置了CredentialProvider时从provider获取，否则使用APIKey和Secret
+// 使用Signer签名时secret可以为空
+func (c *Config) GetCredentials(exchange string) (Credentials, error) {
+	if c.noTestnet {
+		return Credentials{}, ErrNoTestnet
+	}
+	if c.CredentialProvider != nil {
+		return c.CredentialProvider.Credentials(exchange)
+	}
+	if c.APIKey == nil || *c.APIKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	cred := Credentials{APIKey: *c.APIKey}
+	if c.Secret != nil {
+		cred.Secret = *c.Secret
+	}
+	if c.Passphrase != nil {
+		cred.Passphrase = *c.Passphrase
+	}
+	return cred, nil
+}
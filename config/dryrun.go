@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotoxu/log/core"
+)
+
+// DryRunResponder 返回交易请求的模拟响应内容，ok为false表示不是交易请求，照常发送
+type DryRunResponder func(req *http.Request) (body []byte, ok bool)
+
+// WithDryRun 开启模拟交易，下单、撤单、提现和批量操作不会真正发送，
+// 请求仍会完整构造、签名并经过所有中间件和日志，随后返回交易所格式的模拟响应；行情和查询请求照常发送
+func (c *Config) WithDryRun(on bool) *Config {
+	c.DryRun = &on
+	return c
+}
+
+// IsDryRun 返回是否开启了模拟交易
+func (c *Config) IsDryRun() bool {
+	return c.DryRun != nil && *c.DryRun
+}
+
+// ApplyDryRun 开启模拟交易时追加拦截交易请求的中间件，由交易所的NewClient在合并配置后调用，
+// 此时该中间件位于最内层，用户的中间件看到的是与真实发送时相同的请求
+func (c *Config) ApplyDryRun(exchange string, r DryRunResponder) {
+	if !c.IsDryRun() {
+		return
+	}
+	logger := c.ExchangeLogger(exchange)
+	c.WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			body, ok := r(req)
+			if !ok {
+				return next(req)
+			}
+			logger.Logf(core.Info, "dry run: %s %s", req.Method, req.URL.String())
+			return &http.Response{
+				Status:        "200 OK",
+				StatusCode:    http.StatusOK,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{"Content-Type": {"application/json"}, "X-Dry-Run": {"1"}},
+				Body:          ioutil.NopCloser(bytes.NewReader(body)),
+				ContentLength: int64(len(body)),
+				Request:       req,
+			}, nil
+		}
+	})
+}
+
+var dryRunID = time.Now().UnixNano() / int64(time.Millisecond)
+
+// DryRunOrderID 返回一个进程内唯一的模拟订单号
+func DryRunOrderID() int64 {
+	return atomic.AddInt64(&dryRunID, 1)
+}
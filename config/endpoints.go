@@ -0,0 +1,388 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Endpoints 一组可以互相替代的地址(域名[:端口])
+// 根据请求结果和健康检查记录每个地址的延迟和失败情况，Pick返回当前应使用的地址:
+// 当前地址可用时继续使用，除非另一个可用地址的延迟低20%以上；连接失败的地址在退避时间内不会被选择
+type Endpoints struct {
+	mutex   sync.Mutex
+	hosts   []*endpoint
+	current int
+	check   sync.Once
+}
+
+type endpoint struct {
+	host      string
+	latency   time.Duration // 指数加权平均，0表示尚未测量
+	failures  int
+	downUntil time.Time
+}
+
+const (
+	endpointMinBackoff = time.Second
+	endpointMaxBackoff = time.Minute
+)
+
+// NewEndpoints 创建一组地址，排在前面的地址在延迟未知时优先使用，尚未测量的地址排在已测量的可用地址之后
+func NewEndpoints(hosts ...string) *Endpoints {
+	e := &Endpoints{}
+	for _, h := range hosts {
+		e.hosts = append(e.hosts, &endpoint{host: h})
+	}
+	return e
+}
+
+// Hosts 返回所有地址
+func (e *Endpoints) Hosts() []string {
+	hosts := make([]string, len(e.hosts))
+	for i, h := range e.hosts {
+		hosts[i] = h.host
+	}
+	return hosts
+}
+
+// Has 返回host是否属于这组地址
+func (e *Endpoints) Has(host string) bool {
+	for _, h := range e.hosts {
+		if h.host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// Pick 返回当前应使用的地址
+func (e *Endpoints) Pick() string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.hosts[e.pick(time.Now())].host
+}
+
+// Order 返回按优先级排列的所有地址，用于逐个尝试
+func (e *Endpoints) Order() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	now := time.Now()
+	first := e.pick(now)
+	order := []string{e.hosts[first].host}
+	var down []*endpoint
+	for i, h := range e.hosts {
+		if i == first {
+			continue
+		}
+		if h.downUntil.After(now) {
+			down = append(down, h)
+			continue
+		}
+		order = append(order, h.host)
+	}
+	for _, h := range down {
+		order = append(order, h.host)
+	}
+	return order
+}
+
+func (e *Endpoints) pick(now time.Time) int {
+	cur := e.hosts[e.current]
+	best := -1
+	for i, h := range e.hosts {
+		if h.downUntil.After(now) {
+			continue
+		}
+		if best < 0 || faster(h, e.hosts[best]) {
+			best = i
+		}
+	}
+	switch {
+	case best < 0:
+		// 全部不可用时选择最早恢复的地址
+		best = 0
+		for i, h := range e.hosts {
+			if h.downUntil.Before(e.hosts[best].downUntil) {
+				best = i
+			}
+		}
+	case cur.downUntil.After(now):
+		// 当前地址不可用，换用best
+	case !faster(e.hosts[best], cur),
+		cur.latency != 0 && float64(e.hosts[best].latency) > 0.8*float64(cur.latency):
+		best = e.current
+	}
+	e.current = best
+	return best
+}
+
+// faster 返回a是否比b更应该被使用，已测量的地址优先于尚未测量的地址
+func faster(a, b *endpoint) bool {
+	switch {
+	case a.latency == 0:
+		return false
+	case b.latency == 0:
+		return true
+	}
+	return a.latency < b.latency
+}
+
+// Report 记录一次使用host的结果，err不为nil表示连接失败
+func (e *Endpoints) Report(host string, latency time.Duration, err error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for _, h := range e.hosts {
+		if h.host != host {
+			continue
+		}
+		if err != nil {
+			h.failures++
+			backoff := endpointMinBackoff << uint(h.failures-1)
+			if backoff > endpointMaxBackoff || backoff <= 0 {
+				backoff = endpointMaxBackoff
+			}
+			h.downUntil = time.Now().Add(backoff)
+			return
+		}
+		h.failures = 0
+		h.downUntil = time.Time{}
+		if h.latency == 0 {
+			h.latency = latency
+		} else {
+			h.latency = (h.latency*7 + latency*3) / 10
+		}
+		return
+	}
+}
+
+// Check 使用probe检查所有地址并记录结果
+func (e *Endpoints) Check(probe func(host string) error) {
+	var wg sync.WaitGroup
+	for _, h := range e.Hosts() {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			start := time.Now()
+			err := probe(host)
+			e.Report(host, time.Since(start), err)
+		}(h)
+	}
+	wg.Wait()
+}
+
+// StartHealthCheck 立即检查一次，之后每隔interval检查一次，直到ctx结束
+// 对同一个Endpoints只有第一次调用生效
+func (e *Endpoints) StartHealthCheck(ctx context.Context, interval time.Duration, probe func(host string) error) {
+	e.check.Do(func() {
+		go func() {
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			for {
+				e.Check(probe)
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+				}
+			}
+		}()
+	})
+}
+
+// WithRESTHosts 设置rest接口的一组备用地址，第一个地址同时作为RESTHost，没有地址时不做修改
+// 之后只调用WithRESTHost时会清除备用地址；连接失败的请求会立即换用下一个地址重发
+func (c *Config) WithRESTHosts(hosts ...string) *Config {
+	if len(hosts) == 0 {
+		return c
+	}
+	c.RESTHost = &hosts[0]
+	c.RESTHosts = NewEndpoints(hosts...)
+	return c
+}
+
+// WithWSSHosts 设置websocket接口的一组备用地址，第一个地址同时作为WSSHost，没有地址时不做修改
+func (c *Config) WithWSSHosts(hosts ...string) *Config {
+	if len(hosts) == 0 {
+		return c
+	}
+	c.WSSHost = &hosts[0]
+	c.WSSHosts = NewEndpoints(hosts...)
+	return c
+}
+
+// WithHealthCheck 设置备用地址的主动健康检查间隔，检查方式为对每个地址发送HEAD请求，
+// 收到任何http响应即认为可用；未设置时只根据实际请求的结果切换地址
+func (c *Config) WithHealthCheck(interval time.Duration) *Config {
+	c.HealthCheck = &interval
+	return c
+}
+
+// RESTEndpoint 返回当前应使用的rest地址，未设置备用地址时返回RESTHost
+// 需要对域名签名的交易所应在签名前调用并使用同一个地址
+func (c *Config) RESTEndpoint() string {
+	if c.RESTHosts == nil {
+		return *c.RESTHost
+	}
+	c.startHealthCheck(c.RESTHosts)
+	return c.RESTHosts.Pick()
+}
+
+// RESTURL 返回path对应的完整rest地址，域名取自RESTEndpoint，协议由UseSSL决定
+func (c *Config) RESTURL(path string) string {
+	scheme := "https://"
+	if c.UseSSL != nil && !*c.UseSSL {
+		scheme = "http://"
+	}
+	return scheme + c.RESTEndpoint() + path
+}
+
+// DialWSS 建立websocket连接，rawurl的host属于WSSHosts时按优先级依次尝试所有备用地址
+func (c *Config) DialWSS(rawurl string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil || c.WSSHosts == nil || !c.WSSHosts.Has(u.Host) {
+		return c.WSSDialer.Dial(rawurl, header)
+	}
+	c.startHealthCheck(c.WSSHosts)
+	var conn *websocket.Conn
+	var resp *http.Response
+	for _, host := range c.WSSHosts.Order() {
+		u.Host = host
+		start := time.Now()
+		conn, resp, err = c.WSSDialer.Dial(u.String(), header)
+		if err == websocket.ErrBadHandshake {
+			// 服务器有响应，不是地址不可用
+			c.WSSHosts.Report(host, time.Since(start), nil)
+			return conn, resp, err
+		}
+		c.WSSHosts.Report(host, time.Since(start), err)
+		if err == nil {
+			return conn, resp, nil
+		}
+	}
+	return conn, resp, err
+}
+
+type hostBoundKey struct{}
+
+// BindHost 标记签名中包含域名的请求，连接失败时不会换用其他地址重发，
+// 由RetryDo重新调用RESTEndpoint并签名后再请求
+func BindHost(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), hostBoundKey{}, true))
+}
+
+// send 使用HTTPClient发送请求并记录地址的可用情况
+// 地址属于RESTHosts且连接未能建立时请求还没有发出，即使是下单也可以安全地换用其他地址重发
+func (c *Config) send(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	c.reportEndpoint(req, start, resp, err)
+	if err == nil || !isDialError(err) || c.RESTHosts == nil || !c.RESTHosts.Has(req.URL.Host) {
+		return resp, err
+	}
+	if bound, _ := req.Context().Value(hostBoundKey{}).(bool); bound {
+		return resp, err
+	}
+	tried := map[string]bool{req.URL.Host: true}
+	for _, host := range c.RESTHosts.Order() {
+		if tried[host] {
+			continue
+		}
+		tried[host] = true
+		r, ok := retarget(req, host)
+		if !ok {
+			break
+		}
+		start = time.Now()
+		resp, err = c.HTTPClient.Do(r)
+		c.reportEndpoint(r, start, resp, err)
+		if err == nil || !isDialError(err) {
+			return resp, err
+		}
+	}
+	return resp, err
+}
+
+// retarget 复制请求并替换域名，请求体无法重新读取时返回false
+func retarget(req *http.Request, host string) (*http.Request, bool) {
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		r.Body = body
+	}
+	r.URL.Host = host
+	r.Host = ""
+	return r, true
+}
+
+// isDialError 返回err是否为建立连接失败，此时请求还没有发出
+func isDialError(err error) bool {
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
+
+// reportEndpoint 记录rest请求的结果，连接失败和502、503、504视为地址不可用
+func (c *Config) reportEndpoint(req *http.Request, start time.Time, resp *http.Response, err error) {
+	if c.RESTHosts == nil || !c.RESTHosts.Has(req.URL.Host) {
+		return
+	}
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			err = &url.Error{Op: req.Method, URL: req.URL.Host, Err: errUnavailable}
+		}
+	} else if req.Context().Err() != nil {
+		// 调用方取消或超时，与地址无关
+		return
+	}
+	c.RESTHosts.Report(req.URL.Host, time.Since(start), err)
+}
+
+type unavailableError struct{}
+
+func (unavailableError) Error() string { return "service unavailable" }
+
+var errUnavailable error = unavailableError{}
+
+func (c *Config) startHealthCheck(e *Endpoints) {
+	if c.HealthCheck == nil || *c.HealthCheck <= 0 {
+		return
+	}
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client, timeout, scheme := c.HTTPClient, *c.HealthCheck, "https://"
+	if c.UseSSL != nil && !*c.UseSSL {
+		scheme = "http://"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	e.StartHealthCheck(ctx, *c.HealthCheck, func(host string) error {
+		req, err := http.NewRequest("HEAD", scheme+host+"/", nil)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	})
+}
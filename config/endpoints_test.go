@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEndpointsPreferMeasured(t *testing.T) {
+	e := NewEndpoints("a", "b", "c")
+	if h := e.Pick(); h != "a" {
+		t.Fatalf("no measurement: got %s, want a", h)
+	}
+	e.Report("b", 80*time.Millisecond, nil)
+	if h := e.Pick(); h != "b" {
+		t.Fatalf("measured host should win over unmeasured: got %s", h)
+	}
+	e.Report("c", 75*time.Millisecond, nil)
+	if h := e.Pick(); h != "b" {
+		t.Fatalf("less than 20%% faster should not switch: got %s", h)
+	}
+	e.Report("c", 10*time.Millisecond, nil)
+	e.Report("c", 10*time.Millisecond, nil)
+	if h := e.Pick(); h != "c" {
+		t.Fatalf("much faster host should win: got %s", h)
+	}
+	e.Report("c", 0, errors.New("down"))
+	if h := e.Pick(); h != "b" {
+		t.Fatalf("down host should be skipped: got %s", h)
+	}
+	if order := e.Order(); strings.Join(order, ",") != "b,a,c" {
+		t.Fatalf("order %v", order)
+	}
+}
+
+func TestDoFailover(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(r.Method + " " + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	// 一个没有监听的地址
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := l.Addr().String()
+	l.Close()
+	live := strings.TrimPrefix(srv.URL, "http://")
+
+	cfg := (&Config{HTTPClient: &http.Client{}}).WithUseSSL(false).WithRESTHosts(dead, live)
+	req, err := http.NewRequest("POST", cfg.RESTURL("/order"), strings.NewReader("qty=1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Host != dead {
+		t.Fatalf("first pick %s, want %s", req.URL.Host, dead)
+	}
+	resp, err := cfg.Do("test", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Fatalf("request not sent to the live host")
+	}
+	if h := cfg.RESTEndpoint(); h != live {
+		t.Fatalf("after failover got %s, want %s", h, live)
+	}
+
+	// 签名包含域名的请求不换用其他地址
+	cfg = (&Config{HTTPClient: &http.Client{}}).WithUseSSL(false).WithRESTHosts(dead, live)
+	req, _ = http.NewRequest("GET", cfg.RESTURL("/account"), nil)
+	if _, err := cfg.Do("test", BindHost(req)); err == nil {
+		t.Fatal("host bound request should fail")
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Fatalf("host bound request was sent to another host")
+	}
+}
+
+func TestWithHostsEmpty(t *testing.T) {
+	cfg := (&Config{}).WithRESTHost("a").WithWSSHost("b").WithRESTHosts().WithWSSHosts()
+	if *cfg.RESTHost != "a" || *cfg.WSSHost != "b" || cfg.RESTHosts != nil || cfg.WSSHosts != nil {
+		t.Errorf("empty host list changed the config: %+v", cfg)
+	}
+}
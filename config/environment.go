@@ -0,0 +1,57 @@
+package config
+
+import "errors"
+
+// Environment 表示交易所的运行环境
+type Environment int
+
+const (
+	// Production 生产环境
+	Production Environment = iota
+	// Testnet 交易所提供的测试网或模拟盘
+	Testnet
+)
+
+func (e Environment) String() string {
+	if e == Testnet {
+		return "testnet"
+	}
+	return "production"
+}
+
+// ErrNoTestnet 表示在Testnet环境下使用了没有测试网的交易所的签名接口
+var ErrNoTestnet = errors.New("exchange has no testnet, signed requests are disabled")
+
+// WithEnvironment 设置运行环境，Testnet下有测试网的交易所会自动切换所有rest和websocket地址
+// 没有测试网的交易所仍可以查询行情，但所有签名请求都会返回ErrNoTestnet，避免误操作生产账户
+func (c *Config) WithEnvironment(env Environment) *Config {
+	c.Environment = &env
+	return c
+}
+
+// Env 返回配置的运行环境，c为nil或未设置时为Production
+func (c *Config) Env() Environment {
+	if c == nil || c.Environment == nil {
+		return Production
+	}
+	return *c.Environment
+}
+
+// MergeInEnv 与MergeIn相同，合并后的环境为Testnet时先合并交易所的测试网配置sandbox，
+// 因此cfgs中显式设置的地址仍然优先；sandbox为nil表示交易所没有测试网
+func (c *Config) MergeInEnv(sandbox *Config, cfgs ...*Config) {
+	env := c.Env()
+	for _, other := range cfgs {
+		if other != nil && other.Environment != nil {
+			env = *other.Environment
+		}
+	}
+	if env == Testnet {
+		if sandbox == nil {
+			c.noTestnet = true
+		} else {
+			cfgs = append([]*Config{sandbox}, cfgs...)
+		}
+	}
+	c.MergeIn(cfgs...)
+}
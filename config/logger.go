@@ -0,0 +1,41 @@
+package config
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gotoxu/log/core"
+)
+
+// 结构化日志的字段名
+const (
+	LogExchange = "exchange"
+	LogEndpoint = "endpoint"
+	LogSymbol   = "symbol"
+	LogLatency  = "latency"
+)
+
+// ExchangeLogger 返回带有exchange字段的日志器，输出内容中的敏感信息会被替换
+// 未配置Logger时返回静默的日志器，调用方无需判空
+func (c *Config) ExchangeLogger(exchange string) core.Logger {
+	if c.Logger == nil {
+		return nopLogger{}
+	}
+	return RedactLogger(c.Logger).With(LogExchange, exchange)
+}
+
+// nopLogger 丢弃所有日志
+type nopLogger struct{}
+
+func (nopLogger) Log(level core.Level, v ...interface{})                 {}
+func (nopLogger) Logf(level core.Level, format string, v ...interface{}) {}
+func (nopLogger) Logln(level core.Level, v ...interface{})               {}
+func (l nopLogger) With(key string, value interface{}) core.Logger       { return l }
+func (nopLogger) Sync() error                                            { return nil }
+
+// LogResponse 以Debug等级记录一次rest请求的响应，附带endpoint和latency字段
+func LogResponse(logger core.Logger, req *http.Request, start time.Time, body []byte) {
+	logger.With(LogEndpoint, req.URL.Host+req.URL.Path).
+		With(LogLatency, time.Since(start)).
+		Logf(core.Debug, "http response: %s", body)
+}
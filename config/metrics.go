@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+// Metrics 是sdk上报运行指标的接口，实现需要保证并发安全
+// 未配置时不上报任何指标
+type Metrics interface {
+	// ObserveRequest 记录一次rest请求，status为0表示没有收到响应
+	ObserveRequest(exchange, endpoint string, status int, class string, dur time.Duration)
+	// ObserveRateLimitWait 记录一次因交易所限流产生的等待
+	ObserveRateLimitWait(exchange string, wait time.Duration)
+	// IncReconnect 记录一次websocket重连
+	IncReconnect(exchange, stream string)
+	// IncMessage 记录订阅收到的一条消息
+	IncMessage(exchange, subscription string)
+	// IncDrop 记录一条因channel已满而被丢弃的消息
+	IncDrop(exchange, subscription string)
+}
+
+// 请求错误的分类
+const (
+	ErrClassNone      = ""
+	ErrClassHTTP4xx   = "http_4xx"
+	ErrClassHTTP5xx   = "http_5xx"
+	ErrClassRateLimit = "rate_limit"
+	ErrClassTimeout   = "timeout"
+	ErrClassCanceled  = "canceled"
+	ErrClassNetwork   = "network"
+	ErrClassOther     = "other"
+)
+
+// ErrorClass 返回错误所属的分类，用作指标的标签
+func ErrorClass(err error) string {
+	for err != nil {
+		switch e := err.(type) {
+		case *global.HTTPError:
+			switch {
+			case isRateLimited(e):
+				return ErrClassRateLimit
+			case e.StatusCode >= 500:
+				return ErrClassHTTP5xx
+			default:
+				return ErrClassHTTP4xx
+			}
+		case *url.Error:
+			err = e.Err
+		case net.Error:
+			if e.Timeout() {
+				return ErrClassTimeout
+			}
+			return ErrClassNetwork
+		default:
+			switch err {
+			case context.Canceled:
+				return ErrClassCanceled
+			case context.DeadlineExceeded:
+				return ErrClassTimeout
+			case io.EOF, io.ErrUnexpectedEOF:
+				return ErrClassNetwork
+			}
+			return ErrClassOther
+		}
+	}
+	return ErrClassNone
+}
+
+func isRateLimited(e *global.HTTPError) bool {
+	return e.StatusCode == 429 || e.StatusCode == 418
+}
+
+// ObserveRequest 上报一次rest请求，通常在请求函数中defer调用
+func (c *Config) ObserveRequest(exchange, endpoint string, status int, err error, start time.Time) {
+	if c.Metrics != nil {
+		c.Metrics.ObserveRequest(exchange, endpoint, status, ErrorClass(err), time.Since(start))
+	}
+}
+
+// ObserveReconnect 上报一次websocket重连
+func (c *Config) ObserveReconnect(exchange, stream string) {
+	if c.Metrics != nil {
+		c.Metrics.IncReconnect(exchange, stream)
+	}
+}
+
+// ObserveMessage 上报订阅收到的一条消息
+func (c *Config) ObserveMessage(exchange, subscription string) {
+	if c.Metrics != nil {
+		c.Metrics.IncMessage(exchange, subscription)
+	}
+}
+
+// ObserveDrop 上报一条被丢弃的订阅消息
+func (c *Config) ObserveDrop(exchange, subscription string) {
+	if c.Metrics != nil {
+		c.Metrics.IncDrop(exchange, subscription)
+	}
+}
+
+// RetryDo 按Retry策略执行幂等请求，因限流重试时上报等待时间
+func (c *Config) RetryDo(exchange string, fn func() error) error {
+	return c.RetryDoOrder(exchange, fn, func() (bool, error) { return false, nil })
+}
+
+// RetryDoOrder 按Retry策略执行非幂等请求，参见RetryPolicy.DoOrder
+func (c *Config) RetryDoOrder(exchange string, fn func() error, lookup func() (bool, error)) error {
+	return c.Retry.doOrder(c.Context, fn, lookup, func(err error, wait time.Duration) {
+		if e, ok := err.(*global.HTTPError); ok && isRateLimited(e) && c.Metrics != nil {
+			c.Metrics.ObserveRateLimitWait(exchange, wait)
+		}
+	})
+}
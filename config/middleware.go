@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// RoundTripFunc 执行一次http请求
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware 包装rest请求的执行过程，可以修改请求、记录或替换响应
+// 中间件看到的是已签名的原始请求，记录时应使用Redact隐藏其中的密钥和签名
+// 读取了resp.Body的中间件需要用新的reader替换Body，以便后续的处理仍能读到完整内容
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// FrameDirection 表示websocket帧的方向
+type FrameDirection int
+
+const (
+	// FrameRead 从交易所收到的帧
+	FrameRead FrameDirection = iota
+	// FrameWrite 发往交易所的帧
+	FrameWrite
+)
+
+// FrameHook 在每次读写websocket原始帧时被调用，data为未解压的原始数据，不应被修改
+// 文本帧中的敏感信息会先经过Redact处理
+type FrameHook func(exchange string, dir FrameDirection, messageType int, data []byte)
+
+type exchangeKey struct{}
+
+// ExchangeFromContext 返回发起请求的交易所名称，供中间件区分请求来源
+func ExchangeFromContext(ctx context.Context) string {
+	ex, _ := ctx.Value(exchangeKey{}).(string)
+	return ex
+}
+
+// Do 经过所有中间件后使用HTTPClient发送请求，先注册的中间件位于最外层
+func (c *Config) Do(exchange string, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(context.WithValue(req.Context(), exchangeKey{}, exchange))
+	next := RoundTripFunc(c.send)
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		next = c.Middlewares[i](next)
+	}
+	resp, err := next(req)
+	return resp, RedactError(err)
+}
+
+// ReadFrame 读取一个websocket帧并调用FrameHook
+func (c *Config) ReadFrame(exchange string, conn *websocket.Conn) (int, []byte, error) {
+	mt, data, err := conn.ReadMessage()
+	if err == nil {
+		c.frame(exchange, FrameRead, mt, data)
+	}
+	return mt, data, err
+}
+
+// WriteFrame 调用FrameHook后写入一个websocket帧
+func (c *Config) WriteFrame(exchange string, conn *websocket.Conn, messageType int, data []byte) error {
+	c.frame(exchange, FrameWrite, messageType, data)
+	return conn.WriteMessage(messageType, data)
+}
+
+// WriteJSONFrame 将v编码为json后以文本帧写入
+func (c *Config) WriteJSONFrame(exchange string, conn *websocket.Conn, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteFrame(exchange, conn, websocket.TextMessage, data)
+}
+
+func (c *Config) frame(exchange string, dir FrameDirection, messageType int, data []byte) {
+	if len(c.FrameHooks) == 0 {
+		return
+	}
+	if messageType == websocket.TextMessage {
+		if s := Redact(string(data)); s != string(data) {
+			data = []byte(s)
+		}
+	}
+	for _, h := range c.FrameHooks {
+		h(exchange, dir, messageType, data)
+	}
+}
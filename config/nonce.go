@@ -0,0 +1,80 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Nonce 生成签名请求使用的nonce，在所有goroutine间严格递增
+// 取值为以unit为单位的当前时间，与上一次取值冲突时在其基础上加1
+type Nonce struct {
+	mutex  sync.Mutex
+	unit   time.Duration
+	clock  *Clock
+	path   string
+	last   int64
+	loaded bool
+}
+
+// NewNonce 创建一个nonce生成器，unit为时间单位，clock为nil时使用本地时间
+func NewNonce(unit time.Duration, clock *Clock) *Nonce {
+	return &Nonce{unit: unit, clock: clock}
+}
+
+// NewNonce 根据NonceFile配置创建nonce生成器
+func (c *Config) NewNonce(unit time.Duration, clock *Clock) *Nonce {
+	n := NewNonce(unit, clock)
+	if c.NonceFile != nil {
+		n.path = *c.NonceFile
+	}
+	return n
+}
+
+// Next 返回下一个nonce，设置了持久化文件时会先写入文件再返回
+func (n *Nonce) Next() (int64, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if !n.loaded {
+		if err := n.load(); err != nil {
+			return 0, err
+		}
+		n.loaded = true
+	}
+
+	v := n.clock.Now().UnixNano() / int64(n.unit)
+	if v <= n.last {
+		v = n.last + 1
+	}
+	if n.path != "" {
+		err := ioutil.WriteFile(n.path, []byte(strconv.FormatInt(v, 10)), 0600)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n.last = v
+	return v, nil
+}
+
+func (n *Nonce) load() error {
+	if n.path == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(n.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return nil
+	}
+	n.last, err = strconv.ParseInt(s, 10, 64)
+	return err
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit 客户端令牌桶限流，按平均速率放行请求，允许burst个请求的突发
+// 同一个RateLimit可以被多个client共享，用于限制同一账户的总请求频率
+type RateLimit struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimit 创建每per时间内最多requests个请求的限流器，burst小于1时按1处理
+func NewRateLimit(requests int, per time.Duration, burst int) *RateLimit {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimit{
+		rate:   float64(requests) / per.Seconds(),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 等待直到可以发送下一个请求，ctx结束时返回ctx.Err()
+func (l *RateLimit) Wait(ctx context.Context) error {
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		l.tokens++
+		l.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Middleware 返回在发送请求前调用Wait的中间件
+func (l *RateLimit) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := l.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
+
+// WithRateLimit 为rest请求追加客户端限流
+func (c *Config) WithRateLimit(l *RateLimit) *Config {
+	return c.WithMiddleware(l.Middleware())
+}
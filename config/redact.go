@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gotoxu/log/core"
+)
+
+// redacted 替换敏感内容后的占位符
+const redacted = "***"
+
+// sensitiveNames 是参数、header和json字段中视为敏感信息的名称，匹配时不区分大小写
+const sensitiveNames = `apikey|api_key|api-key|accesskey|access_key|accesskeyid|access_id|key|secret|secretkey|secret_key|` +
+	`sign|signature|listenkey|token|access_token|passphrase|authorization|` +
+	`ok-access-key|ok-access-sign|ok-access-passphrase|x-mbx-apikey`
+
+var redactRules = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// url query和表单: key=value
+	{regexp.MustCompile(`(?i)(^|[?&;\s"'(])(` + sensitiveNames + `)=([^&\s"')]+)`), "${1}${2}=" + redacted},
+	// json: "key":"value"
+	{regexp.MustCompile(`(?i)("(` + sensitiveNames + `)"\s*:\s*")(?:[^"\\]|\\.)*"`), "${1}" + redacted + `"`},
+	// http.Header.Write等输出的header行: Name: value
+	{regexp.MustCompile(`(?im)^((` + sensitiveNames + `):[ \t]*)[^\r\n]+`), "${1}" + redacted},
+	// Authorization的值: Bearer token
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/=]+`), "${1} " + redacted},
+	// %+v输出的结构体和map: Key:value
+	{regexp.MustCompile(`(?i)([{\[\s,](` + sensitiveNames + `):\[?)[^\s}\],]+`), "${1}" + redacted},
+	// binance用户数据流地址中的listenKey
+	{regexp.MustCompile(`(/ws/)[A-Za-z0-9]{60}\b`), "${1}" + redacted},
+}
+
+// Redact 将s中的api key、secret、签名、listenKey和token等替换为***
+// sdk输出的日志、返回的错误和FrameHook收到的数据都已经过处理，
+// 自定义Middleware看到的是原始请求，需要记录时可以使用此函数
+func Redact(s string) string {
+	for _, r := range redactRules {
+		s = r.re.ReplaceAllString(s, r.repl)
+	}
+	return s
+}
+
+// RedactError 返回隐藏了敏感信息的错误
+// *url.Error会复制一份并处理URL，保留原有的错误类型；其他错误仅在内容包含敏感信息时被替换
+func RedactError(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *url.Error:
+		c := *e
+		c.URL = Redact(e.URL)
+		return &c
+	}
+	if msg := Redact(err.Error()); msg != err.Error() {
+		return &redactedError{msg: msg, err: err}
+	}
+	return err
+}
+
+// redactedError 以处理后的内容代替原始错误的内容
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+// Cause 返回原始错误
+func (e *redactedError) Cause() error { return e.err }
+
+// RedactLogger 返回对所有输出内容调用Redact的日志器，logger为nil时返回nil
+func RedactLogger(logger core.Logger) core.Logger {
+	if logger == nil {
+		return nil
+	}
+	if _, ok := logger.(redactLogger); ok {
+		return logger
+	}
+	return redactLogger{logger}
+}
+
+type redactLogger struct {
+	l core.Logger
+}
+
+func (r redactLogger) Log(level core.Level, v ...interface{}) {
+	r.l.Log(level, Redact(fmt.Sprint(v...)))
+}
+
+func (r redactLogger) Logf(level core.Level, format string, v ...interface{}) {
+	r.l.Log(level, Redact(fmt.Sprintf(format, v...)))
+}
+
+func (r redactLogger) Logln(level core.Level, v ...interface{}) {
+	r.l.Logln(level, Redact(strings.TrimSuffix(fmt.Sprintln(v...), "\n")))
+}
+
+func (r redactLogger) With(key string, value interface{}) core.Logger {
+	if redactKey.MatchString(key) {
+		value = redacted
+	} else if s, ok := value.(string); ok {
+		value = Redact(s)
+	} else if s := fmt.Sprint(value); Redact(s) != s {
+		// http.Header、结构体等非字符串的值包含敏感信息时以处理后的字符串代替
+		value = Redact(s)
+	}
+	return redactLogger{r.l.With(key, value)}
+}
+
+func (r redactLogger) Sync() error {
+	return r.l.Sync()
+}
+
+var redactKey = regexp.MustCompile(`(?i)^(` + sensitiveNames + `)$`)
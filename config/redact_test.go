@@ -0,0 +1,321 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
+)
+
+// 测试中使用的密钥，任何输出中都不能出现
+const (
+	testAPIKey     = "vmPUZE6mv9SD5VNHk4HlWFsOr6aKE2zvsw0MuIgwCIPy6utIco14y7Ju91duEh8A"
+	testSecret     = "NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j"
+	testSignature  = "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71"
+	testOKSign     = "F6xS+8zdL/JrT2HmpQ0aWcN9eK1bVy3uIoPgRtYs4Lk="
+	testPassphrase = "p4ssphr4se-9f2c"
+	testListenKey  = "pqia91ma19a5s61cv6a81va65sdf19v8a65a1a5s61cv6a81va65sdf19v8a"
+	testToken      = "tok-3b8f0e7d1c2a"
+)
+
+var testSecrets = []string{testAPIKey, testSecret, testSignature, testOKSign, testPassphrase, testListenKey, testToken}
+
+func assertRedacted(t *testing.T, name, out string) {
+	t.Helper()
+	for _, s := range testSecrets {
+		if strings.Contains(out, s) {
+			t.Errorf("%s: output contains secret %q:\n%s", name, s, out)
+		}
+	}
+}
+
+// testHeader 返回带有各交易所鉴权header的http.Header
+func testHeader() http.Header {
+	h := http.Header{}
+	h.Set("X-MBX-APIKEY", testAPIKey)
+	h.Set("OK-ACCESS-KEY", testAPIKey)
+	h.Set("OK-ACCESS-SIGN", testOKSign)
+	h.Set("OK-ACCESS-PASSPHRASE", testPassphrase)
+	h.Set("OK-ACCESS-TIMESTAMP", "2020-12-08T09:08:57.715Z")
+	h.Set("Authorization", "Bearer "+testToken)
+	h.Set("Content-Type", "application/json")
+	return h
+}
+
+func headerDump(h http.Header) string {
+	var b strings.Builder
+	h.Write(&b)
+	return b.String()
+}
+
+var redactCases = []struct {
+	name string
+	in   string
+	keep string // 处理后仍应保留的内容
+}{
+	{
+		"binance signed url",
+		"https://api.binance.com/api/v3/order?symbol=BTCUSDT&side=BUY&timestamp=1499827319559&signature=" + testSignature,
+		"symbol=BTCUSDT&side=BUY",
+	},
+	{
+		"query with apikey and secret",
+		"apikey=" + testAPIKey + "&secret_key=" + testSecret + "&sign=" + testSignature + "&limit=5",
+		"limit=5",
+	},
+	{
+		"huobi signed query",
+		"GET https://api.huobi.pro/v1/order/orders?AccessKeyId=" + testAPIKey + "&SignatureMethod=HmacSHA256&Signature=" + url.QueryEscape(testOKSign),
+		"SignatureMethod=HmacSHA256",
+	},
+	{
+		"binance user stream url",
+		"wss://stream.binance.com:9443/ws/" + testListenKey,
+		"stream.binance.com",
+	},
+	{
+		"json body",
+		`{"apiKey":"` + testAPIKey + `","secret":"` + testSecret + `","passphrase":"` + testPassphrase + `","listenKey":"` + testListenKey + `","symbol":"BTCUSDT"}`,
+		`"symbol":"BTCUSDT"`,
+	},
+	{
+		"escaped json body",
+		`{"op":"login","args":[{"apiKey":"` + testAPIKey + `","passphrase":"` + testPassphrase + `","sign":"` + testOKSign + `"}]}`,
+		`"op":"login"`,
+	},
+	{
+		"header map",
+		fmt.Sprintf("request header %v", testHeader()),
+		"Content-Type",
+	},
+	{
+		"header dump",
+		headerDump(testHeader()),
+		"Ok-Access-Timestamp: 2020-12-08T09:08:57.715Z",
+	},
+	{
+		"struct",
+		fmt.Sprintf("%+v", struct {
+			APIKey     string
+			Secret     string
+			Passphrase string
+			Token      string
+		}{testAPIKey, testSecret, testPassphrase, testToken}),
+		"APIKey:",
+	},
+	{
+		"form body",
+		"access_token=" + testToken + "&accesskey=" + testAPIKey + "&method=order",
+		"method=order",
+	},
+}
+
+func TestRedact(t *testing.T) {
+	for _, tt := range redactCases {
+		out := Redact(tt.in)
+		assertRedacted(t, tt.name, out)
+		if !strings.Contains(out, tt.keep) {
+			t.Errorf("%s: %q was removed:\n%s", tt.name, tt.keep, out)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	for _, tt := range redactCases {
+		assertRedacted(t, tt.name, RedactError(errors.New(tt.in)).Error())
+	}
+
+	signed := "https://api.binance.com/api/v3/account?timestamp=1&signature=" + testSignature
+	ue := &url.Error{Op: "Get", URL: signed, Err: errors.New("connection refused")}
+	err := RedactError(ue)
+	if _, ok := err.(*url.Error); !ok {
+		t.Errorf("RedactError changed the error type to %T", err)
+	}
+	assertRedacted(t, "url.Error", err.Error())
+	if ue.URL != signed {
+		t.Error("RedactError modified the original error")
+	}
+
+	plain := errors.New("insufficient balance")
+	if RedactError(plain) != plain {
+		t.Error("RedactError replaced an error without secrets")
+	}
+	if RedactError(nil) != nil {
+		t.Error("RedactError(nil) != nil")
+	}
+}
+
+// recordLogger 记录所有输出，包括With添加的字段
+type recordLogger struct {
+	mu     *sync.Mutex
+	out    *[]string
+	fields string
+}
+
+func newRecordLogger() recordLogger {
+	return recordLogger{mu: &sync.Mutex{}, out: &[]string{}}
+}
+
+func (l recordLogger) add(s string) {
+	l.mu.Lock()
+	*l.out = append(*l.out, l.fields+s)
+	l.mu.Unlock()
+}
+
+func (l recordLogger) Log(level core.Level, v ...interface{}) { l.add(fmt.Sprint(v...)) }
+func (l recordLogger) Logf(level core.Level, format string, v ...interface{}) {
+	l.add(fmt.Sprintf(format, v...))
+}
+func (l recordLogger) Logln(level core.Level, v ...interface{}) { l.add(fmt.Sprintln(v...)) }
+func (l recordLogger) With(key string, value interface{}) core.Logger {
+	l.fields += fmt.Sprintf("%s=%v ", key, value)
+	return l
+}
+func (l recordLogger) Sync() error { return nil }
+
+func (l recordLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(*l.out, "\n")
+}
+
+func TestRedactLogger(t *testing.T) {
+	rec := newRecordLogger()
+	logger := RedactLogger(rec)
+	if RedactLogger(logger) != logger {
+		t.Error("RedactLogger wrapped twice")
+	}
+	if RedactLogger(nil) != nil {
+		t.Error("RedactLogger(nil) != nil")
+	}
+	for _, tt := range redactCases {
+		logger.Log(core.Info, "log ", tt.in)
+		logger.Logf(core.Info, "logf %s", tt.in)
+		logger.Logln(core.Info, "logln", tt.in)
+		logger.With("body", tt.in).Log(core.Info, tt.name)
+	}
+	logger.With("secret", testSecret).With("listenKey", testListenKey).Log(core.Info, "fields")
+	logger.With("header", testHeader()).Log(core.Info, "header field")
+	assertRedacted(t, "RedactLogger", rec.String())
+	if !strings.Contains(rec.String(), "symbol=BTCUSDT") {
+		t.Error("RedactLogger removed non-sensitive content")
+	}
+}
+
+func TestExchangeLogger(t *testing.T) {
+	rec := newRecordLogger()
+	cfg := &Config{Logger: rec}
+	cfg.ExchangeLogger("okex").Logf(core.Warn, "login %s", redactCases[5].in)
+	out := rec.String()
+	assertRedacted(t, "ExchangeLogger", out)
+	if !strings.Contains(out, LogExchange+"=okex") {
+		t.Errorf("missing exchange field: %s", out)
+	}
+}
+
+func TestLogResponse(t *testing.T) {
+	rec := newRecordLogger()
+	logger := (&Config{Logger: rec}).ExchangeLogger("binance")
+	req, err := http.NewRequest("GET", "https://api.binance.com/api/v3/userDataStream?signature="+testSignature, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header = testHeader()
+	body := `{"listenKey":"` + testListenKey + `","secret":"` + testSecret + `"}`
+	LogResponse(logger, req, time.Now(), []byte(body))
+	out := rec.String()
+	assertRedacted(t, "LogResponse", out)
+	if !strings.Contains(out, "api.binance.com/api/v3/userDataStream") {
+		t.Errorf("missing endpoint: %s", out)
+	}
+}
+
+func TestDoRedactsError(t *testing.T) {
+	cfg := &Config{HTTPClient: &http.Client{}}
+	// 没有服务监听的地址，错误中包含完整的请求地址
+	req, err := http.NewRequest("GET", "http://127.0.0.1:1/api/v3/order?apikey="+testAPIKey+"&signature="+testSignature, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cfg.Do("binance", req)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	assertRedacted(t, "Do", err.Error())
+}
+
+func TestFrameHooks(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(mt, data)
+		}
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var frames []string
+	cfg := (&Config{}).WithFrameHook(func(exchange string, dir FrameDirection, messageType int, data []byte) {
+		mu.Lock()
+		frames = append(frames, fmt.Sprintf("%s %d %d %s", exchange, dir, messageType, data))
+		mu.Unlock()
+	})
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	login := map[string]interface{}{
+		"op": "login",
+		"args": []map[string]string{{
+			"apiKey":     testAPIKey,
+			"passphrase": testPassphrase,
+			"timestamp":  "1538054050",
+			"sign":       testOKSign,
+		}},
+	}
+	if err := cfg.WriteJSONFrame("okex", conn, login); err != nil {
+		t.Fatal(err)
+	}
+	if _, data, err := cfg.ReadFrame("okex", conn); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(data), testAPIKey) {
+		// 只有FrameHook看到的内容被处理，调用方读到的仍是原始数据
+		t.Errorf("ReadFrame returned modified data: %s", data)
+	}
+	for _, tt := range redactCases {
+		if err := cfg.WriteFrame("binance", conn, websocket.TextMessage, []byte(tt.in)); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := cfg.ReadFrame("binance", conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	out := strings.Join(frames, "\n")
+	n := len(frames)
+	mu.Unlock()
+	if n != 2*(len(redactCases)+1) {
+		t.Errorf("got %d frames, want %d", n, 2*(len(redactCases)+1))
+	}
+	assertRedacted(t, "FrameHook", out)
+}
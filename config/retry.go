@@ -0,0 +1,122 @@
+package config
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+// RetryPolicy rest请求遇到临时性错误(5xx、超时、连接被重置)时的重试策略
+// 为nil时不进行任何重试
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，不包含第一次请求
+	MinBackoff time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxBackoff time.Duration // 等待时间的上限
+}
+
+// DefaultRetryPolicy 返回默认的重试策略: 最多重试3次，等待时间200ms起，不超过5s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		MinBackoff: 200 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+	}
+}
+
+// Do 执行一个幂等请求(查询行情、资金、订单状态等)，遇到临时性错误时按策略重试
+func (p *RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	return p.DoOrder(ctx, fn, func() (bool, error) { return false, nil })
+}
+
+// DoOrder 执行一个非幂等请求(下单、提现等)
+// lookup 用于在重试之前确认原请求是否已经被交易所受理(一般通过客户端订单号查询)，
+// 返回true表示已受理，此时不再重试，直接返回成功；lookup为nil时不进行任何重试
+func (p *RetryPolicy) DoOrder(ctx context.Context, fn func() error, lookup func() (bool, error)) error {
+	return p.doOrder(ctx, fn, lookup, nil)
+}
+
+// doOrder onWait不为nil时，每次重试等待前都会被调用
+func (p *RetryPolicy) doOrder(ctx context.Context, fn func() error, lookup func() (bool, error),
+	onWait func(err error, wait time.Duration)) error {
+	err := fn()
+	if p == nil || lookup == nil {
+		return err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for i := 0; i < p.MaxRetries && err != nil && IsTransient(err); i++ {
+		wait := p.backoff(i)
+		if onWait != nil {
+			onWait(err, wait)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		ok, lerr := lookup()
+		if lerr != nil {
+			// 无法确认原请求的结果，不能冒险再发一次
+			return err
+		}
+		if ok {
+			return nil
+		}
+		err = fn()
+	}
+	return err
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	if d <= 0 {
+		d = 100 * time.Millisecond
+	}
+	for i := 0; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			d = p.MaxBackoff
+			break
+		}
+	}
+	// 加入随机抖动，避免多个客户端同时重试
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// IsTransient 判断错误是否为可以重试的临时性错误
+func IsTransient(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case *global.HTTPError:
+			return e.Temporary()
+		case *url.Error:
+			if e.Timeout() {
+				return true
+			}
+			err = e.Err
+		case *net.OpError:
+			if e.Timeout() {
+				return true
+			}
+			err = e.Err
+		case *os.SyscallError:
+			err = e.Err
+		case syscall.Errno:
+			return e == syscall.ECONNRESET || e == syscall.ECONNABORTED ||
+				e == syscall.ECONNREFUSED || e == syscall.EPIPE
+		case net.Error:
+			return e.Timeout()
+		default:
+			return err == io.EOF || err == io.ErrUnexpectedEOF
+		}
+	}
+	return false
+}
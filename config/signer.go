@@ -0,0 +1,38 @@
+package config
+
+import "errors"
+
+// Signer 对请求的待签名内容签名，返回交易所要求的编码后的签名
+// 各交易所的签名方式见signer包，使用远程签名时secret可以不出现在交易进程中
+type Signer interface {
+	Sign(payload []byte) (string, error)
+}
+
+// Scheme 使用secret创建某种签名方式的Signer
+type Scheme func(secret string) Signer
+
+// ErrNoSecret 表示既没有配置Signer也没有可用的secret
+var ErrNoSecret = errors.New("no secret or signer configured")
+
+// WithSigner 设置签名器，设置后不再使用Secret或CredentialProvider返回的secret签名
+// Signer需要实现对应交易所的签名方式
+func (c *Config) WithSigner(s Signer) *Config {
+	c.Signer = s
+	return c
+}
+
+// Signing 返回exchange签名请求使用的凭证和签名器
+// 设置了Signer时直接使用，否则以当前凭证中的secret调用scheme创建签名器
+func (c *Config) Signing(exchange string, scheme Scheme) (Credentials, Signer, error) {
+	cred, err := c.GetCredentials(exchange)
+	if err != nil {
+		return cred, nil, err
+	}
+	if c.Signer != nil {
+		return cred, c.Signer, nil
+	}
+	if cred.Secret == "" {
+		return cred, nil, ErrNoSecret
+	}
+	return cred, scheme(cred.Secret), nil
+}
@@ -0,0 +1,38 @@
+package credential
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// Env 从环境变量读取凭证，每次调用时重新读取
+// 变量名为 Prefix + 大写的交易所名 + _APIKEY/_SECRET/_PASSPHRASE，
+// 例如Prefix为"EXSDK_"时huobi使用EXSDK_HUOBI_APIKEY和EXSDK_HUOBI_SECRET
+type Env struct {
+	Prefix string
+}
+
+var _ config.CredentialProvider = (*Env)(nil)
+
+// NewEnv 创建一个以prefix为变量名前缀的Env
+func NewEnv(prefix string) *Env {
+	return &Env{Prefix: prefix}
+}
+
+// Credentials 返回exchange的凭证，APIKEY未设置时返回错误
+// 使用config.Signer签名时可以不设置SECRET
+func (e *Env) Credentials(exchange string) (config.Credentials, error) {
+	name := e.Prefix + strings.ToUpper(exchange) + "_"
+	c := config.Credentials{
+		APIKey:     os.Getenv(name + "APIKEY"),
+		Secret:     os.Getenv(name + "SECRET"),
+		Passphrase: os.Getenv(name + "PASSPHRASE"),
+	}
+	if c.APIKey == "" {
+		return config.Credentials{}, fmt.Errorf("credential: 环境变量%sAPIKEY未设置", name)
+	}
+	return c, nil
+}
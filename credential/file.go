@@ -0,0 +1,124 @@
+package credential
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/blockcdn-go/exchange-sdk-go/utils"
+)
+
+// File 从json或yaml文件读取凭证，扩展名为.yaml或.yml时按yaml解析
+// 文件以交易所名称分节，名称不区分大小写，例如:
+//
+//	huobi:
+//	  apikey: xxx
+//	  secret: xxx
+//	okex:
+//	  apikey: xxx
+//	  secret: xxx
+//	  passphrase: xxx
+//
+// 文件的修改时间或大小变化后会在下次调用时重新加载，轮换密钥只需替换文件，
+// 替换时应先写临时文件再rename，避免读到不完整的内容
+type File struct {
+	source
+}
+
+var _ config.CredentialProvider = (*File)(nil)
+
+// NewFile 创建一个File并立即加载一次，文件不存在或格式错误时返回错误
+func NewFile(path string) (*File, error) {
+	f := &File{source{path: path, parse: parseSections(path)}}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Credentials 返回exchange的凭证
+func (f *File) Credentials(exchange string) (config.Credentials, error) {
+	return f.get(exchange)
+}
+
+// parseSections 根据文件扩展名选择解析方式
+func parseSections(path string) func([]byte) (map[string]config.Credentials, error) {
+	yaml := false
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		yaml = true
+	}
+	return func(data []byte) (map[string]config.Credentials, error) {
+		if yaml {
+			var err error
+			if data, err = utils.YAMLToJSONFor(data, map[string]config.Credentials{}); err != nil {
+				return nil, err
+			}
+		}
+		return decodeSections(data)
+	}
+}
+
+func decodeSections(data []byte) (map[string]config.Credentials, error) {
+	var raw map[string]config.Credentials
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	sections := make(map[string]config.Credentials, len(raw))
+	for name, c := range raw {
+		sections[strings.ToLower(name)] = c
+	}
+	return sections, nil
+}
+
+// source 缓存从文件解析出的凭证，文件变化后重新加载
+type source struct {
+	path  string
+	parse func([]byte) (map[string]config.Credentials, error)
+
+	mutex    sync.Mutex
+	mod      time.Time
+	size     int64
+	sections map[string]config.Credentials
+}
+
+func (s *source) get(exchange string) (config.Credentials, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.reload(); err != nil {
+		return config.Credentials{}, err
+	}
+	c, ok := s.sections[strings.ToLower(exchange)]
+	if !ok || c.APIKey == "" {
+		return config.Credentials{}, fmt.Errorf("credential: %s中没有%s的apikey", s.path, exchange)
+	}
+	return c, nil
+}
+
+// reload 在文件变化时重新解析，解析失败时保留之前的内容并返回错误
+// 返回的错误包装了解析错误，可以用errors.Is判断ErrKeystorePassphrase
+func (s *source) reload() error {
+	fi, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	if s.sections != nil && fi.ModTime().Equal(s.mod) && fi.Size() == s.size {
+		return nil
+	}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	sections, err := s.parse(data)
+	if err != nil {
+		return fmt.Errorf("credential: 解析%s失败: %w", s.path, err)
+	}
+	s.sections, s.mod, s.size = sections, fi.ModTime(), fi.Size()
+	return nil
+}
@@ -0,0 +1,33 @@
+package credential
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileNumericSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "keys.yaml")
+	data := "OKEX:\n  apikey: 123456\n  secret: 007\n  passphrase: 123456\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := f.Credentials("okex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.APIKey != "123456" || c.Secret != "007" || c.Passphrase != "123456" {
+		t.Errorf("unexpected credentials %+v", c)
+	}
+}
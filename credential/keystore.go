@@ -0,0 +1,144 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+const (
+	keystoreVersion    = 1
+	keystoreKDF        = "pbkdf2-sha256"
+	keystoreIterations = 100000
+)
+
+// ErrKeystorePassphrase 表示口令错误或keystore文件已损坏
+var ErrKeystorePassphrase = errors.New("credential: keystore口令错误或文件已损坏")
+
+// keystoreFile 是keystore文件的格式，各交易所的凭证以json编码后使用AES-256-GCM加密，
+// 密钥由口令经PBKDF2-HMAC-SHA256派生
+type keystoreFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Keystore 从口令加密的本地文件读取凭证，文件由WriteKeystore生成
+// 与File一样，文件变化后会在下次调用时重新加载，口令需要保存在内存中用于重新解密
+type Keystore struct {
+	source
+}
+
+var _ config.CredentialProvider = (*Keystore)(nil)
+
+// NewKeystore 使用口令打开keystore文件并立即解密一次
+func NewKeystore(path, passphrase string) (*Keystore, error) {
+	k := &Keystore{source{path: path, parse: func(data []byte) (map[string]config.Credentials, error) {
+		return openKeystore(data, passphrase)
+	}}}
+	if err := k.reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Credentials 返回exchange的凭证
+func (k *Keystore) Credentials(exchange string) (config.Credentials, error) {
+	return k.get(exchange)
+}
+
+// WriteKeystore 使用口令加密sections并写入path，sections以交易所名称为key
+// 先写入同目录下的临时文件再rename，正在使用该文件的Keystore不会读到不完整的内容
+func WriteKeystore(path, passphrase string, sections map[string]config.Credentials) error {
+	plain, err := json.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	ks := keystoreFile{
+		Version:    keystoreVersion,
+		KDF:        keystoreKDF,
+		Iterations: keystoreIterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(ks.Salt); err != nil {
+		return err
+	}
+	gcm, err := keystoreCipher(passphrase, ks.Salt, ks.Iterations)
+	if err != nil {
+		return err
+	}
+	ks.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(ks.Nonce); err != nil {
+		return err
+	}
+	ks.Ciphertext = gcm.Seal(nil, ks.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(0600)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func openKeystore(data []byte, passphrase string) (map[string]config.Credentials, error) {
+	var ks keystoreFile
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, err
+	}
+	if ks.Version != keystoreVersion || ks.KDF != keystoreKDF || ks.Iterations <= 0 {
+		return nil, fmt.Errorf("不支持的keystore版本%d(%s)", ks.Version, ks.KDF)
+	}
+	gcm, err := keystoreCipher(passphrase, ks.Salt, ks.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(ks.Nonce) != gcm.NonceSize() {
+		return nil, ErrKeystorePassphrase
+	}
+	plain, err := gcm.Open(nil, ks.Nonce, ks.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrKeystorePassphrase
+	}
+	return decodeSections(plain)
+}
+
+func keystoreCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,181 @@
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+func tempKeystore(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "keys.json"), func() { os.RemoveAll(dir) }
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	path, cleanup := tempKeystore(t)
+	defer cleanup()
+
+	sections := map[string]config.Credentials{
+		"Binance": {APIKey: "bn-key", Secret: "bn-secret"},
+		"okex":    {APIKey: "ok-key", Secret: "ok-secret", Passphrase: "ok-pass"},
+	}
+	if err := WriteKeystore(path, "correct horse", sections); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if fi.Mode().Perm() != 0600 {
+		t.Errorf("keystore mode %v, want 0600", fi.Mode().Perm())
+	}
+	k, err := NewKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]config.Credentials{"binance": sections["Binance"], "OKEX": sections["okex"]} {
+		c, err := k.Credentials(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != want {
+			t.Errorf("%s: got %+v, want %+v", name, c, want)
+		}
+	}
+	if _, err := k.Credentials("huobi"); err == nil {
+		t.Error("missing section should fail")
+	}
+}
+
+func TestKeystoreWrongPassphrase(t *testing.T) {
+	path, cleanup := tempKeystore(t)
+	defer cleanup()
+
+	if err := WriteKeystore(path, "right", map[string]config.Credentials{"binance": {APIKey: "key"}}); err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewKeystore(path, "wrong")
+	if !errors.Is(err, ErrKeystorePassphrase) {
+		t.Fatalf("got %v, want ErrKeystorePassphrase", err)
+	}
+	if k != nil {
+		t.Error("wrong passphrase returned a keystore")
+	}
+}
+
+func TestKeystoreCorrupted(t *testing.T) {
+	path, cleanup := tempKeystore(t)
+	defer cleanup()
+
+	if err := WriteKeystore(path, "pass", map[string]config.Credentials{"binance": {APIKey: "key", Secret: "secret"}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ks keystoreFile
+	if err := json.Unmarshal(data, &ks); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(ks *keystoreFile)
+	}{
+		{"tampered ciphertext", func(ks *keystoreFile) { ks.Ciphertext[0] ^= 1 }},
+		{"truncated ciphertext", func(ks *keystoreFile) { ks.Ciphertext = ks.Ciphertext[:len(ks.Ciphertext)-1] }},
+		{"tampered salt", func(ks *keystoreFile) { ks.Salt[0] ^= 1 }},
+		{"short nonce", func(ks *keystoreFile) { ks.Nonce = ks.Nonce[:4] }},
+	}
+	for _, tt := range tests {
+		c := ks
+		c.Salt = append([]byte(nil), ks.Salt...)
+		c.Nonce = append([]byte(nil), ks.Nonce...)
+		c.Ciphertext = append([]byte(nil), ks.Ciphertext...)
+		tt.modify(&c)
+		b, _ := json.Marshal(c)
+		if err := ioutil.WriteFile(path, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewKeystore(path, "pass"); !errors.Is(err, ErrKeystorePassphrase) {
+			t.Errorf("%s: got %v, want ErrKeystorePassphrase", tt.name, err)
+		}
+	}
+
+	// 文件本身被截断
+	if err := ioutil.WriteFile(path, data[:len(data)/2], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewKeystore(path, "pass"); err == nil {
+		t.Error("truncated file should fail")
+	}
+}
+
+func TestKeystoreRotation(t *testing.T) {
+	path, cleanup := tempKeystore(t)
+	defer cleanup()
+
+	if err := WriteKeystore(path, "pass", map[string]config.Credentials{"binance": {APIKey: "old-key"}}); err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewKeystore(path, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := k.Credentials("binance"); err != nil || c.APIKey != "old-key" {
+		t.Fatalf("before rotation: %+v %v", c, err)
+	}
+
+	rotate := func(passphrase, key string) {
+		t.Helper()
+		if err := WriteKeystore(path, passphrase, map[string]config.Credentials{"binance": {APIKey: key}}); err != nil {
+			t.Fatal(err)
+		}
+		// 保证修改时间变化
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rotate("pass", "new-key")
+	if c, err := k.Credentials("binance"); err != nil || c.APIKey != "new-key" {
+		t.Fatalf("after rotation: %+v %v", c, err)
+	}
+
+	// 换成其他口令加密的文件时返回错误，不会返回错误解密的内容
+	rotate("other", "other-key")
+	if _, err := k.Credentials("binance"); !errors.Is(err, ErrKeystorePassphrase) {
+		t.Errorf("rotated with another passphrase: got %v", err)
+	}
+}
+
+// TestKeystoreCompat 之前版本写入的keystore仍能打开
+func TestKeystoreCompat(t *testing.T) {
+	path, cleanup := tempKeystore(t)
+	defer cleanup()
+
+	data := `{"ciphertext":"i6t+WkF91RwfNojfYxUDrSrbOoEJvoL8axf/pDRZx1snd+EQWhvGCOmN/SNvb1Lw+qCd1WQJRWZc+zswWxRXQp9xam+EbQ==",` +
+		`"iterations":1000,"kdf":"pbkdf2-sha256","nonce":"bm9uY2UtMTJieXRl","salt":"MDEyMzQ1Njc4OWFiY2RlZg==","version":1}`
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewKeystore(path, "old passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := k.Credentials("binance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.APIKey != "old-key" || c.Secret != "old-secret" {
+		t.Errorf("unexpected credentials %+v", c)
+	}
+}
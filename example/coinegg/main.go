@@ -2,15 +2,12 @@ package main
 
 import (
 	"fmt"
-	"net/http"
 	"net/url"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 
-	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/coinegg"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
-	"github.com/gorilla/websocket"
 )
 
 func main() {
@@ -20,13 +17,8 @@ func main() {
 	sa2 := "6Q3Y2-Ndhmx-(RsrH-/~YdM-)Ff1b-phKL5-ZRU;y"
 	cfg.WithAPIKey(sa1)
 	cfg.WithSecret(sa2)
-	dialer := websocket.DefaultDialer
 	u, _ := url.Parse("http://127.0.0.1:1080")
-	dialer.Proxy = http.ProxyURL(u)
-	cfg.WithWSSDialer(dialer)
-	transport := clean.DefaultPooledTransport()
-	transport.Proxy = http.ProxyURL(u)
-	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithProxy(u)
 	c := coinegg.NewClient(cfg)
 
 	sm, err := c.GetAllSymbol()
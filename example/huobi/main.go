@@ -5,10 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"net/url"
 
-	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 	//	"net/http"
 	//	"net/url"
@@ -18,16 +16,13 @@ import (
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/huobi"
-	"github.com/gorilla/websocket"
 )
 
 func main() {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, syscall.SIGTERM, syscall.SIGINT)
 
-	dialer := websocket.DefaultDialer
 	u, _ := url.Parse("http://127.0.0.1:1080")
-	dialer.Proxy = http.ProxyURL(u)
 	f, _ := os.Open("../cfg.json")
 	js, _ := ioutil.ReadAll(f)
 	cjs := struct {
@@ -71,10 +66,7 @@ func main() {
 	// r6, e6 := c.GetMatchDetail("3640838737")
 	// fmt.Println("GetMatchDetail: ", r6, e6)
 
-	cfg.WithWSSDialer(dialer)
-	transport := clean.DefaultPooledTransport()
-	transport.Proxy = http.ProxyURL(u)
-	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithProxy(u)
 	wss := huobi.NewClient(cfg)
 
 	pair := global.TradeSymbol{Base: "btc", Quote: "usdt"}
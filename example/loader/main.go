@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/loader"
+)
+
+func main() {
+	exs, err := loader.Load("exchanges.yaml", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gate, err := exs.API("gate")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fund, err := gate.GetFund(global.FundReq{})
+	fmt.Println("gate GetFund: ", fund, err)
+}
@@ -8,6 +8,8 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/okex"
 )
 
@@ -15,25 +17,37 @@ func main() {
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, syscall.SIGTERM, syscall.SIGINT)
 
-	c := okex.NewClient(nil)
-	tk, e := c.Login("18200202750", "huang123")
-	fmt.Println("Login: ", tk, e)
+	cfg := &config.Config{}
+	cfg.WithAPIKey(os.Getenv("OKEX_APIKEY")).WithSecret(os.Getenv("OKEX_SECRET")).WithPassphrase(os.Getenv("OKEX_PASSPHRASE"))
+	c := okex.NewClient(cfg)
+	symbol := global.TradeSymbol{Base: "BTC", Quote: "USDT"}
+
+	d, e := c.GetDepth(symbol)
+	fmt.Println("GetDepth: ", d, e)
+	f, e := c.GetFund(global.FundReq{})
+	fmt.Println("GetFund: ", f, e)
+
+	tk, e := c.SubTicker(symbol)
+	if e != nil {
+		log.Fatal("sub ticker error: ", e)
+	}
+	for {
+		select {
+		case t := <-tk:
+			fmt.Printf("%+v\n", t)
+		case <-interrupt:
+			return
+		}
+	}
 	// ctx, cancel := context.WithCancel(context.Background())
 	// go wss(ctx, "1")
 	// go wss(ctx, "2")
-
-	// for {
-	// 	select {
-	// 	case <-interrupt:
-	// 		cancel()
-	// 		return
-	// 	}
-	// }
 }
 
 func wss(ctx context.Context, id string) {
 	wss := okex.NewWSSClient(nil)
-	msgCh, err := wss.QuerySpot()
+	wss.Subscribe(okex.Tickers("BTC", "USDT"), okex.Books("BTC", "USDT"))
+	msgCh, err := wss.Query()
 	if err != nil {
 		log.Fatal("query error: ", err)
 	}
@@ -41,7 +55,7 @@ func wss(ctx context.Context, id string) {
 	for {
 		select {
 		case m := <-msgCh:
-			fmt.Printf("ID: %s, message: %s\n", id, string(m))
+			fmt.Printf("ID: %s, message: %+v\n", id, m)
 		case <-ctx.Done():
 			wss.Close()
 			return
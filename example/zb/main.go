@@ -2,14 +2,11 @@ package main
 
 import (
 	"fmt"
-	"net/http"
 	"net/url"
 
-	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/zb"
-	"github.com/gorilla/websocket"
 )
 
 func main() {
@@ -19,13 +16,8 @@ func main() {
 	sa2 := "ffaa6b9d-e38f-4bf4-a355-c85c3b9db511"
 	cfg.WithAPIKey(sa1)
 	cfg.WithSecret(sa2)
-	dialer := websocket.DefaultDialer
 	u, _ := url.Parse("http://127.0.0.1:1080")
-	dialer.Proxy = http.ProxyURL(u)
-	cfg.WithWSSDialer(dialer)
-	transport := clean.DefaultPooledTransport()
-	transport.Proxy = http.ProxyURL(u)
-	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithProxy(u)
 	c := zb.NewClient(cfg)
 
 	sm, err := c.GetAllSymbol()
@@ -6,7 +6,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
 	"github.com/json-iterator/go"
 )
 
@@ -165,38 +169,53 @@ func convkv(dst map[string]float64, src map[string]string) error {
 	return nil
 }
 
-//////////////////////////////////////////////////////////////////////////
-/////////////////////////////////////////////////////////////////////////
-func (c *Client) httpReq(method, path string, in interface{}, out interface{}) error {
-	r := c.newRequest(method, *c.config.RESTHost, path)
+// privatePrefix 需要签名的接口路径前缀
+const privatePrefix = "/api2/1/private/"
+
+// ////////////////////////////////////////////////////////////////////////
+// ///////////////////////////////////////////////////////////////////////
+func (c *Client) httpReq(method, path string, in interface{}, out interface{}) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.config.ObserveRequest("gate", endpoint, status, err, start) }()
+	ctx, cancel := c.config.RequestContext()
+	defer cancel()
+
+	r := c.newRequest(method, c.config.RESTEndpoint(), path)
+	r.ctx = ctx
+	var params string
 	if in != nil {
-		body, params, err := c.encodeFormBody(in)
+		if r.body, params, err = c.encodeFormBody(in); err != nil {
+			return err
+		}
+	}
+	// 只有private接口需要签名，行情接口在没有凭证或Testnet下也可以调用
+	if strings.HasPrefix(path, privatePrefix) {
+		cred, s, err := c.config.Signing("gate", signer.HMACSHA512Hex)
 		if err != nil {
 			return err
 		}
-		r.body = body
-		r.sign = c.sign(params)
-	} else {
-		r.sign = c.sign("")
+		r.key = cred.APIKey
+		if r.sign, err = s.Sign([]byte(params)); err != nil {
+			return err
+		}
 	}
 
 	resp, err := c.doRequest(r)
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	if strings.Contains(path, "/api2/1/private/") && !strings.Contains(path, "getOrder") {
-		fmt.Printf("http message: %s\n", string(body))
-	}
+	config.LogResponse(c.logger, resp.Request, start, body)
 
 	// extra.RegisterFuzzyDecoders()
 
@@ -206,3 +225,10 @@ func (c *Client) httpReq(method, path string, in interface{}, out interface{}) e
 	}
 	return nil
 }
+
+// httpReqRetry 按配置的重试策略执行幂等的httpReq请求
+func (c *Client) httpReqRetry(method, path string, in interface{}, out interface{}) error {
+	return c.config.RetryDo("gate", func() error {
+		return c.httpReq(method, path, in, out)
+	})
+}
@@ -2,16 +2,11 @@ package gate
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha512"
-	"encoding/hex"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
@@ -37,13 +32,15 @@ type Client struct {
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(nil, config)
 	}
+	cfg.ApplyDryRun("gate", dryRun)
 
 	extra.RegisterFuzzyDecoders()
 
 	return &Client{
 		config:        *cfg,
+		logger:        cfg.ExchangeLogger("gate"),
 		tick:          make(map[global.TradeSymbol]chan global.Ticker),
 		depth:         make(map[global.TradeSymbol]chan global.Depth),
 		latetrade:     make(map[global.TradeSymbol]chan global.LateTrade),
@@ -53,7 +50,7 @@ func NewClient(config *config.Config) *Client {
 
 // SetLogger 设置日志器
 func (c *Client) SetLogger(logger core.Logger) {
-	c.logger = logger
+	c.logger = config.RedactLogger(logger)
 }
 
 func (c *Client) log(level core.Level, v ...interface{}) {
@@ -76,7 +73,6 @@ func (c *Client) logln(level core.Level, v ...interface{}) {
 
 func (c *Client) newRequest(method, endpoint, path string) *request {
 	r := &request{
-		config: &c.config,
 		method: method,
 		params: make(map[string][]string),
 		header: make(http.Header),
@@ -104,21 +100,7 @@ func (c *Client) doRequest(r *request) (*http.Response, error) {
 		return nil, err
 	}
 
-	client := &http.Client{
-		Transport: &http.Transport{
-			Dial: func(netw, addr string) (net.Conn, error) {
-				conn, err := net.DialTimeout(netw, addr, time.Second*5) //设置建立连接超时
-				if err != nil {
-					return nil, err
-				}
-				conn.SetDeadline(time.Now().Add(time.Second * 5)) //设置发送接受数据超时
-				return conn, nil
-			},
-			ResponseHeaderTimeout: time.Second * 5,
-		},
-	}
-	return client.Do(req)
-	//return c.config.HTTPClient.Do(req)
+	return c.config.Do("gate", req)
 }
 
 func (c *Client) encodeFormBody(obj interface{}) (io.Reader, string, error) {
@@ -135,22 +117,13 @@ func (c *Client) encodeFormBody(obj interface{}) (io.Reader, string, error) {
 	return strings.NewReader(form.Encode()), form.Encode(), nil
 }
 
-func (c *Client) sign(params string) string {
-	key := []byte(*c.config.Secret)
-	mac := hmac.New(sha512.New, key)
-	mac.Write([]byte(params))
-
-	hash := mac.Sum(nil)
-	return hex.EncodeToString(hash)
-}
-
 type request struct {
-	config *config.Config
 	method string
 	url    *url.URL
 	params url.Values
 	body   io.Reader
 	header http.Header
+	key    string
 	sign   string
 	ctx    context.Context
 }
@@ -168,7 +141,7 @@ func (r *request) toHTTP() (*http.Request, error) {
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	if r.sign != "" {
-		req.Header.Set("key", *r.config.APIKey)
+		req.Header.Set("key", r.key)
 		req.Header.Set("sign", r.sign)
 	}
 
@@ -2,6 +2,7 @@ package gate
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
@@ -16,6 +17,7 @@ func defaultConfig() *config.Config {
 	// u, _ := url.Parse("http://127.0.0.1:8118")
 	// transport.Proxy = http.ProxyURL(u)
 	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithTimeout(5 * time.Second)
 	cfg.WithUseSSL(true)
 
 	return cfg
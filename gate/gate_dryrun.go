@@ -0,0 +1,25 @@
+package gate
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单、撤单和提现的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	if req.Method != "POST" {
+		return nil, false
+	}
+	switch req.URL.Path {
+	case "/api2/1/private/buy", "/api2/1/private/sell":
+		return []byte(fmt.Sprintf(`{"result":"true","orderNumber":"%d","message":"dry run"}`,
+			config.DryRunOrderID())), true
+	case "/api2/1/private/cancelOrder":
+		return []byte(`{"result":true,"code":0,"message":"dry run"}`), true
+	case "/api2/1/private/withdraw":
+		return []byte(`{"result":"true","message":"dry run"}`), true
+	}
+	return nil, false
+}
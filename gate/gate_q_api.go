@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 // GetAllSymbol 交易市场详细行情接口
@@ -36,7 +37,7 @@ func (c *Client) GetDepth(sreq global.TradeSymbol) (global.Depth, error) {
 		Bids [][]float64 `json:"bids"` //买方深度
 	}{}
 
-	e := c.httpReq("GET", path, nil, &t)
+	e := c.httpReqRetry("GET", path, nil, &t)
 	if e != nil {
 		return global.Depth{}, e
 	}
@@ -116,7 +117,7 @@ func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
 		Data    [][]float64 `json:"data"`
 	}{}
 
-	e := c.httpReq("GET", path, nil, &rsp)
+	e := c.httpReqRetry("GET", path, nil, &rsp)
 	if e != nil {
 		return nil, e
 	}
@@ -126,7 +127,7 @@ func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
 	k := []global.Kline{}
 	for i := 0; i < len(rsp.Data); i++ {
 		if len(rsp.Data[i]) < 6 {
-			fmt.Println("gate len(rsp.Data[i]) < 6")
+			c.logger.Logln(core.Warn, "gate len(rsp.Data[i]) < 6")
 			continue
 		}
 		k = append(k, global.Kline{
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 // SubTicker ...
@@ -41,7 +42,7 @@ func (c *Client) loopTicker() {
 		r := map[string]TickerResponse{}
 		err := c.httpReq("GET", "/api2/1/tickers", nil, &r)
 		if err != nil {
-			fmt.Printf("/api2/1/tickers error: %s\n", err.Error())
+			c.logger.Logf(core.Warn, "/api2/1/tickers error: %s", err.Error())
 			time.Sleep(10 * time.Second)
 			continue
 		}
@@ -53,7 +54,8 @@ func (c *Client) loopTicker() {
 			tch, ok := c.tick[key]
 			c.mutex.Unlock()
 			if ok {
-				tch <- global.Ticker{
+				c.config.ObserveMessage("gate", "ticker")
+				t := global.Ticker{
 					Base:               base,
 					Quote:              quote,
 					PriceChange:        v.Last * (v.PercentChange / 100),
@@ -63,6 +65,11 @@ func (c *Client) loopTicker() {
 					LowPrice:           v.Low24hr,
 					Volume:             v.BaseVolume,
 				}
+				select {
+				case tch <- t:
+				default:
+					c.config.ObserveDrop("gate", "ticker")
+				}
 			}
 		}
 		//
@@ -78,7 +85,7 @@ func (c *Client) loopDepth() {
 		}{}
 		err := c.httpReq("GET", "/api2/1/orderBooks", nil, &r)
 		if err != nil {
-			fmt.Printf("/api2/1/orderBooks error: %s\n", err.Error())
+			c.logger.Logf(core.Warn, "/api2/1/orderBooks error: %s", err.Error())
 			time.Sleep(10 * time.Second)
 			continue
 		}
@@ -133,7 +140,12 @@ func (c *Client) loopDepth() {
 				})
 			}
 
-			dch <- dp
+			c.config.ObserveMessage("gate", "depth")
+			select {
+			case dch <- dp:
+			default:
+				c.config.ObserveDrop("gate", "depth")
+			}
 		}
 		//
 		time.Sleep(10 * time.Second)
@@ -169,7 +181,8 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 			for _, td := range rsp.Data {
 				// 去重，不重复的进行推送，重复的不管
 				if !c.findSameLateTrade(td) {
-					ch <- global.LateTrade{
+					c.config.ObserveMessage("gate", "trade:"+sreq.Base+sreq.Quote)
+					lt := global.LateTrade{
 						Base:      sreq.Base,
 						Quote:     sreq.Quote,
 						DateTime:  td.DateTime,
@@ -178,6 +191,11 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 						Dircetion: td.Dircetion,
 						Total:     td.Total,
 					}
+					select {
+					case ch <- lt:
+					default:
+						c.config.ObserveDrop("gate", "trade:"+sreq.Base+sreq.Quote)
+					}
 				}
 			}
 
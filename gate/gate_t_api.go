@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 // GetFund 获取帐号资金余额
@@ -16,7 +17,7 @@ func (c *Client) GetFund(global.FundReq) ([]global.Fund, error) {
 		Available map[string]string `json:"available"`
 		Locked    map[string]string `json:"locked"`
 	}{}
-	e := c.httpReq("POST", path, nil, &b)
+	e := c.httpReqRetry("POST", path, nil, &b)
 	if e != nil {
 		return nil, e
 	}
@@ -138,7 +139,7 @@ func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 		Message string    `json:"message"`
 		Order   OrderInfo `json:"order"`
 	}{}
-	e := c.httpReq("POST", "/api2/1/private/getOrder", arg, &r)
+	e := c.httpReqRetry("POST", "/api2/1/private/getOrder", arg, &r)
 	if e != nil {
 		return global.StatusRsp{}, e
 	}
@@ -162,6 +163,6 @@ func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 		m.Status = global.CANCELED
 		m.StatusMsg = "已撤单"
 	}
-	fmt.Printf("gateio order status %+v\n", or)
+	c.logger.Logf(core.Debug, "gateio order status %+v", or)
 	return m, nil
 }
@@ -88,6 +88,9 @@ type InsertReq struct {
 	Num       float64 `json:"num"`
 	Type      int     `json:"type"`      // 0 - limit, 1- market
 	Direction int     `json:"direction"` // 0 - buy, 1- sell
+	// 客户端自定义订单号，交易所支持时随订单提交，
+	// 下单请求失败后sdk据此确认订单是否已经生效，再决定是否重试
+	ClientOrderID string `json:"clientorderid"`
 }
 
 // InsertRsp 请求下单返回
@@ -127,6 +130,22 @@ type StatusRsp struct {
 	StatusMsg  string  `json:"statusmsg"`
 }
 
+// Order 订单的完整信息，用于查询历史订单
+type Order struct {
+	Base          string  `json:"base"`  // eg BTC
+	Quote         string  `json:"quote"` // eg USDT
+	OrderNo       string  `json:"orderno"`
+	ClientOrderID string  `json:"clientorderid"`
+	Type          int     `json:"type"`       // 0 - limit, 1- market
+	Direction     int     `json:"direction"`  // 0 - buy, 1- sell
+	Price         float64 `json:"price"`      // 委托价格，市价单为0
+	Num           float64 `json:"num"`        // 委托数量
+	TradePrice    float64 `json:"tradeprice"` // 成交均价，没有成交时为0
+	TradeNum      float64 `json:"tradenum"`   // 累计成交数量
+	Status        int     `json:"status"`     // 同StatusRsp.Status
+	Timestamp     int64   `json:"time"`       // 下单时间，毫秒
+}
+
 // CancelReq 撤单请求参数
 type CancelReq struct {
 	APIKey  string `json:"apikey"` // weex 需要
@@ -135,7 +154,34 @@ type CancelReq struct {
 	OrderNo string `json:"orderno"`
 }
 
+// OrderUpdate 订单变动推送
+type OrderUpdate struct {
+	Base          string  `json:"base"`  // eg BTC
+	Quote         string  `json:"quote"` // eg USDT
+	OrderNo       string  `json:"orderno"`
+	ClientOrderID string  `json:"clientorderid"`
+	Type          int     `json:"type"`       // 0 - limit, 1- market
+	Direction     int     `json:"direction"`  // 0 - buy, 1- sell
+	Price         float64 `json:"price"`      // 委托价格，市价单为0
+	Num           float64 `json:"num"`        // 委托数量，市价买单为计价币金额
+	TradePrice    float64 `json:"tradeprice"` // 本次成交价格，没有成交时为0
+	TradeNum      float64 `json:"tradenum"`   // 本次成交数量
+	Fee           float64 `json:"fee"`        // 本次成交手续费
+	FeeAsset      string  `json:"feeasset"`   // 手续费币种
+	Status        int     `json:"status"`     // 同StatusRsp.Status
+	Timestamp     int64   `json:"time"`       // 事件时间，毫秒
+}
+
+// BalanceUpdate 资金变动推送
+type BalanceUpdate struct {
+	Base      string  `json:"base"`      // e.g BTC
+	Available float64 `json:"available"` // 可用
+	Frozen    float64 `json:"frozen"`    // 冻结
+	Timestamp int64   `json:"time"`      // 事件时间，毫秒
+}
+
 // WSif websocket实时推送需要实现的接口
+// 订阅返回的channel缓冲100条，调用方读取不及时时新消息会被丢弃，并通过Metrics.IncDrop上报
 type WSif interface {
 	// 订阅ticker
 	SubTicker(TradeSymbol) (chan Ticker, error)
@@ -0,0 +1,18 @@
+package global
+
+import "fmt"
+
+// HTTPError rest接口返回了非200的响应码
+type HTTPError struct {
+	StatusCode int
+}
+
+// Error ...
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("请求失败，响应码：%d", e.StatusCode)
+}
+
+// Temporary 服务端错误、限频和请求超时属于临时性错误，可以重试
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == 429 || e.StatusCode == 408
+}
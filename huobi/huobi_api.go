@@ -1,7 +1,9 @@
 package huobi
 
 import (
+	"errors"
 	"fmt"
+	"time"
 )
 
 // GetAllAccountID 获取用户的所有accountid
@@ -13,7 +15,7 @@ func (c *Client) GetAllAccountID() ([]Account, error) {
 		Data   []Account `json:"data"`
 		Errmsg string    `json:"err-msg"`
 	}{}
-	e := c.doHTTP("GET", "/v1/account/accounts", nil, &r)
+	e := c.doHTTPRetry("GET", "/v1/account/accounts", nil, &r)
 	if e != nil {
 		return nil, e
 	}
@@ -23,6 +25,23 @@ func (c *Client) GetAllAccountID() ([]Account, error) {
 	return r.Data, nil
 }
 
+// serverTime 查询服务器时间
+func (c *Client) serverTime() (time.Time, error) {
+	r := struct {
+		Status string `json:"status"`
+		Data   int64  `json:"data"`
+		Errmsg string `json:"err-msg"`
+	}{}
+	e := c.doPublicHTTP("GET", "/v1/common/timestamp", nil, &r)
+	if e != nil {
+		return time.Time{}, e
+	}
+	if r.Status != "ok" {
+		return time.Time{}, errors.New(r.Errmsg)
+	}
+	return time.Unix(0, r.Data*int64(time.Millisecond)), nil
+}
+
 // GetKline 获取k线数据
 // func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
 
@@ -1,11 +1,7 @@
 package huobi
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -15,6 +11,8 @@ import (
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
+	"github.com/gotoxu/log/core"
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/gorilla/websocket"
@@ -24,6 +22,8 @@ import (
 // Client 是huobi sdk的调用客户端
 type Client struct {
 	config    config.Config
+	clock     *config.Clock
+	logger    core.Logger
 	replay    bool
 	once      sync.Once
 	sock      *websocket.Conn
@@ -31,21 +31,31 @@ type Client struct {
 	tick      map[global.TradeSymbol]chan global.Ticker
 	depth     map[global.TradeSymbol]chan global.Depth
 	latetrade map[global.TradeSymbol]chan global.LateTrade
+	books     map[global.TradeSymbol]*mbpBook
+
+	accountMu sync.Mutex
+	accounts  []Account
+	private   private
 }
 
 // NewClient 创建一个新的websocket客户端
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(testnetConfig(), config)
 	}
+	cfg.ApplyDryRun("huobi", dryRun)
 
-	return &Client{
+	c := &Client{
 		config:    *cfg,
+		logger:    cfg.ExchangeLogger("huobi"),
 		tick:      make(map[global.TradeSymbol]chan global.Ticker),
 		depth:     make(map[global.TradeSymbol]chan global.Depth),
 		latetrade: make(map[global.TradeSymbol]chan global.LateTrade),
+		books:     make(map[global.TradeSymbol]*mbpBook),
 	}
+	c.clock = cfg.StartClock(c.serverTime)
+	return c
 }
 
 func (c *Client) generateClientID() string {
@@ -54,28 +64,50 @@ func (c *Client) generateClientID() string {
 }
 
 func (c *Client) doHTTP(method, path string, mapParams map[string]string, out interface{}) error {
+	return c.request(method, path, mapParams, true, out)
+}
+
+// doPublicHTTP 请求不需要签名的公共接口，没有配置密钥时也可以调用
+func (c *Client) doPublicHTTP(method, path string, mapParams map[string]string, out interface{}) error {
+	return c.request(method, path, mapParams, false, out)
+}
 
-	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05")
+func (c *Client) request(method, path string, mapParams map[string]string, signed bool, out interface{}) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.config.ObserveRequest("huobi", endpoint, status, err, start) }()
 
 	mapParams2Sign := make(map[string]string)
-	mapParams2Sign["AccessKeyId"] = *c.config.APIKey
-	mapParams2Sign["SignatureMethod"] = "HmacSHA256"
-	mapParams2Sign["SignatureVersion"] = "2"
-	mapParams2Sign["Timestamp"] = timestamp
-	for k, v := range mapParams {
-		mapParams2Sign[k] = v
+	if method != "POST" {
+		// POST的参数放在请求体中，不参与签名
+		for k, v := range mapParams {
+			mapParams2Sign[k] = v
+		}
+	}
+	hostName := c.config.RESTEndpoint()
+
+	if signed {
+		cred, s, err := c.config.Signing("huobi", signer.HMACSHA256Base64)
+		if err != nil {
+			return err
+		}
+		mapParams2Sign["AccessKeyId"] = cred.APIKey
+		mapParams2Sign["SignatureMethod"] = "HmacSHA256"
+		mapParams2Sign["SignatureVersion"] = "2"
+		mapParams2Sign["Timestamp"] = c.clock.Now().UTC().Format("2006-01-02T15:04:05")
+		mapParams2Sign["Signature"], err = createSign(mapParams2Sign, method, hostName, path, s)
+		if err != nil {
+			return err
+		}
 	}
-	hostName := *c.config.RESTHost
-
-	mapParams2Sign["Signature"] = createSign(mapParams2Sign, method, hostName,
-		path, *c.config.Secret)
 
 	url := "http://"
 	if *c.config.UseSSL {
 		url = "https://"
 	}
-	url += *c.config.RESTHost + path
-	url += "?" + map2UrlQuery(mapParams2Sign)
+	url += hostName + path
+	if len(mapParams2Sign) != 0 {
+		url += "?" + map2UrlQuery(mapParams2Sign)
+	}
 
 	arg := ""
 	if method == "POST" {
@@ -95,27 +127,30 @@ func (c *Client) doHTTP(method, path string, mapParams map[string]string, out in
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) "+
 		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
 
-	resp, re := c.config.HTTPClient.Do(req)
+	ctx, cancel := c.config.RequestContext()
+	defer cancel()
+	req = req.WithContext(ctx)
+	if signed {
+		// 签名包含域名，换用其他地址时需要重新签名
+		req = config.BindHost(req)
+	}
+	resp, re := c.config.Do("huobi", req)
 	if re != nil {
 		return re
 	}
+	status = resp.StatusCode
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	if !strings.Contains(path, "/v1/common/symbols") &&
-		!strings.Contains(path, "/market/history/kline") &&
-		!strings.Contains(path, "/v1/account/accounts") &&
-		!strings.Contains(path, "/v1/order/orders") {
-		fmt.Printf("huobi http message:%s\n", string(body))
-	}
+	config.LogResponse(c.logger, req, start, body)
 
 	//extra.RegisterFuzzyDecoders()
 
@@ -126,9 +161,16 @@ func (c *Client) doHTTP(method, path string, mapParams map[string]string, out in
 	return nil
 }
 
+// doHTTPRetry 按配置的重试策略执行幂等的doHTTP请求
+func (c *Client) doHTTPRetry(method, path string, mapParams map[string]string, out interface{}) error {
+	return c.config.RetryDo("huobi", func() error {
+		return c.doHTTP(method, path, mapParams, out)
+	})
+}
+
 // 构造签名
 func createSign(mapParams map[string]string, strMethod, strHostURL,
-	strRequestPath, strSecretKey string) string {
+	strRequestPath string, s config.Signer) (string, error) {
 	// 参数处理, 按API要求, 参数名应按ASCII码进行排序(使用UTF-8编码, 其进行URI编码, 16进制字符必须大写)
 	strParams := valURIQuery(mapSort(mapParams))
 
@@ -137,11 +179,7 @@ func createSign(mapParams map[string]string, strMethod, strHostURL,
 		strRequestPath + "\n" +
 		strParams
 
-	key := []byte(strSecretKey)
-	h := hmac.New(sha256.New, key)
-	h.Write([]byte(strPayload))
-
-	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+	return s.Sign([]byte(strPayload))
 }
 
 func map2UrlQuery(mapParams map[string]string) string {
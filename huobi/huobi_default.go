@@ -19,5 +19,14 @@ func defaultConfig() *config.Config {
 	cfg.WithHTTPClient(clean.DefaultPooledClient())
 	cfg.WithWSSDialer(websocket.DefaultDialer)
 	cfg.WithUseSSL(true)
+	cfg.WithDepthStep("step0")
+	return cfg
+}
+
+// testnetConfig 火币测试网
+func testnetConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.WithWSSHost("api.testnet.huobi.pro")
+	cfg.WithRESTHost("api.testnet.huobi.pro")
 	return cfg
 }
@@ -0,0 +1,44 @@
+package huobi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单、撤单、批量撤单和提现的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	if req.Method != "POST" {
+		return nil, false
+	}
+	switch p := req.URL.Path; {
+	case p == "/v1/order/orders/place", p == "/v1/dw/withdraw/api/create":
+		return []byte(fmt.Sprintf(`{"status":"ok","data":"%d"}`, config.DryRunOrderID())), true
+	case strings.HasPrefix(p, "/v1/order/orders/") && strings.HasSuffix(p, "/submitcancel"):
+		id := strings.TrimSuffix(strings.TrimPrefix(p, "/v1/order/orders/"), "/submitcancel")
+		return []byte(fmt.Sprintf(`{"status":"ok","data":%q}`, id)), true
+	case p == "/v1/order/orders/batchcancel":
+		arg := struct {
+			OrderIDs []string `json:"order-ids"`
+		}{}
+		if req.Body != nil {
+			// 位于中间件的最内层，读取请求体不影响其他中间件
+			if b, err := ioutil.ReadAll(req.Body); err == nil {
+				json.Unmarshal(b, &arg)
+			}
+		}
+		if arg.OrderIDs == nil {
+			arg.OrderIDs = []string{}
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"status": "ok",
+			"data":   map[string]interface{}{"success": arg.OrderIDs, "failed": []string{}},
+		})
+		return data, true
+	}
+	return nil, false
+}
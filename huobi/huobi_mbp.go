@@ -0,0 +1,235 @@
+package huobi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
+)
+
+// mbpMaxPending 等待快照期间最多缓存的增量推送数
+const mbpMaxPending = 1000
+
+// mbpTick mbp频道的增量推送和req请求返回的快照
+type mbpTick struct {
+	SeqNum     int64       `json:"seqNum"`
+	PrevSeqNum int64       `json:"prevSeqNum"`
+	Asks       [][]float64 `json:"asks"`
+	Bids       [][]float64 `json:"bids"`
+}
+
+// mbpBook 根据mbp增量推送在本地维护的深度
+// 收到快照之前的增量推送先缓存，快照之后按seqNum/prevSeqNum逐条校验并应用
+type mbpBook struct {
+	seq     int64
+	synced  bool
+	pending []mbpTick
+	asks    map[float64]float64
+	bids    map[float64]float64
+}
+
+func newMBPBook() *mbpBook {
+	b := &mbpBook{}
+	b.reset()
+	return b
+}
+
+// reset 丢弃本地深度，等待新的快照
+func (b *mbpBook) reset() {
+	b.seq = 0
+	b.synced = false
+	b.pending = nil
+	b.asks = make(map[float64]float64)
+	b.bids = make(map[float64]float64)
+}
+
+// update 处理一条增量推送，返回深度是否有变化
+// 返回错误表示推送不连续，需要reset后重新获取快照
+func (b *mbpBook) update(t mbpTick) (bool, error) {
+	if !b.synced {
+		if len(b.pending) >= mbpMaxPending {
+			b.pending = b.pending[1:]
+		}
+		b.pending = append(b.pending, t)
+		return false, nil
+	}
+	if t.SeqNum <= b.seq {
+		// 快照中已经包含
+		return false, nil
+	}
+	if t.PrevSeqNum != b.seq {
+		return false, fmt.Errorf("huobipro mbp seqNum不连续: 本地%d, 推送prevSeqNum %d", b.seq, t.PrevSeqNum)
+	}
+	b.apply(t)
+	return true, nil
+}
+
+// snapshot 使用快照重建深度并应用缓存的增量推送
+func (b *mbpBook) snapshot(t mbpTick) error {
+	pending := b.pending
+	b.reset()
+	b.synced = true
+	b.apply(t)
+	for _, p := range pending {
+		if _, err := b.update(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *mbpBook) apply(t mbpTick) {
+	set := func(side map[float64]float64, levels [][]float64) {
+		for _, l := range levels {
+			if len(l) < 2 {
+				continue
+			}
+			if l[1] == 0 {
+				delete(side, l[0])
+			} else {
+				side[l[0]] = l[1]
+			}
+		}
+	}
+	set(b.asks, t.Asks)
+	set(b.bids, t.Bids)
+	b.seq = t.SeqNum
+}
+
+// depth 返回卖方价格升序、买方价格降序的深度，每边最多levels档
+func (b *mbpBook) depth(key global.TradeSymbol, levels int) global.Depth {
+	side := func(m map[float64]float64, desc bool) []global.DepthPair {
+		prices := make([]float64, 0, len(m))
+		for p := range m {
+			prices = append(prices, p)
+		}
+		if desc {
+			sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+		} else {
+			sort.Float64s(prices)
+		}
+		if levels > 0 && len(prices) > levels {
+			prices = prices[:levels]
+		}
+		r := make([]global.DepthPair, 0, len(prices))
+		for _, p := range prices {
+			r = append(r, global.DepthPair{Price: p, Size: m[p]})
+		}
+		return r
+	}
+	return global.Depth{
+		Base:  key.Base,
+		Quote: key.Quote,
+		Asks:  side(b.asks, false),
+		Bids:  side(b.bids, true),
+	}
+}
+
+// mbpFeedLevels 150档及以上的mbp增量深度只能通过/feed订阅
+const mbpFeedLevels = 150
+
+// errFeedOnly /feed连接只能订阅mbp深度
+var errFeedOnly = errors.New("huobipro 150档mbp深度使用/feed连接，只能订阅深度，ticker和成交请使用另一个Client")
+
+// marketPath 返回行情连接的路径，150档mbp使用/feed，其他使用/ws
+func (c *Client) marketPath() string {
+	if c.depthLevels() >= mbpFeedLevels {
+		return "/feed"
+	}
+	return "/ws"
+}
+
+// depthLevels 返回增量深度的档数，为0时使用全量深度推送
+func (c *Client) depthLevels() int {
+	if c.config.DepthLevels == nil {
+		return 0
+	}
+	return *c.config.DepthLevels
+}
+
+// depthTopic 返回深度行情的频道
+func (c *Client) depthTopic(key global.TradeSymbol) string {
+	symbol := strings.ToLower(key.Base + key.Quote)
+	if levels := c.depthLevels(); levels > 0 {
+		return fmt.Sprintf("market.%s.mbp.%d", symbol, levels)
+	}
+	return fmt.Sprintf("market.%s.depth.%s", symbol, *c.config.DepthStep)
+}
+
+// requestSnapshot 通过req请求mbp快照，调用方需持有c.mutex
+func (c *Client) requestSnapshot(key global.TradeSymbol) error {
+	req := struct {
+		Topic string `json:"req"`
+		ID    string `json:"id"`
+	}{c.depthTopic(key), c.generateClientID()}
+	return c.config.WriteJSONFrame("huobi", c.sock, req)
+}
+
+// parseMBP 处理增量推送，不连续时重新请求快照
+func (c *Client) parseMBP(key global.TradeSymbol, t mbpTick, msg []byte) {
+	c.mutex.Lock()
+	ch, ok := c.depth[key]
+	b := c.books[key]
+	if !ok || b == nil {
+		c.mutex.Unlock()
+		c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
+		return
+	}
+	changed, err := b.update(t)
+	if err != nil {
+		c.logger.Logf(core.Warn, "%s，重新获取快照", err.Error())
+		b.reset()
+		if err = c.requestSnapshot(key); err != nil {
+			c.logger.Logf(core.Warn, "请求快照失败 %s", err.Error())
+		}
+	}
+	var d global.Depth
+	if changed {
+		d = b.depth(key, c.depthLevels())
+	}
+	c.mutex.Unlock()
+	if changed {
+		c.config.ObserveMessage("huobi", "depth:"+key.Base+key.Quote)
+		select {
+		case ch <- d:
+		default:
+			c.config.ObserveDrop("huobi", "depth:"+key.Base+key.Quote)
+		}
+	}
+}
+
+// parseSnapshot 处理req请求返回的快照
+func (c *Client) parseSnapshot(key global.TradeSymbol, status string, t mbpTick, msg []byte) {
+	c.mutex.Lock()
+	ch, ok := c.depth[key]
+	b := c.books[key]
+	if !ok || b == nil {
+		c.mutex.Unlock()
+		return
+	}
+	if status != "ok" {
+		c.mutex.Unlock()
+		c.logger.Logf(core.Warn, "huobipro 获取快照失败 %s", string(msg))
+		return
+	}
+	if err := b.snapshot(t); err != nil {
+		c.logger.Logf(core.Warn, "%s，重新获取快照", err.Error())
+		b.reset()
+		if err = c.requestSnapshot(key); err != nil {
+			c.logger.Logf(core.Warn, "请求快照失败 %s", err.Error())
+		}
+		c.mutex.Unlock()
+		return
+	}
+	d := b.depth(key, c.depthLevels())
+	c.mutex.Unlock()
+	c.config.ObserveMessage("huobi", "depth:"+key.Base+key.Quote)
+	select {
+	case ch <- d:
+	default:
+		c.config.ObserveDrop("huobi", "depth:"+key.Base+key.Quote)
+	}
+}
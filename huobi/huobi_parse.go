@@ -2,17 +2,23 @@ package huobi
 
 import (
 	"encoding/json"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 func (c *Client) parse(msg []byte) {
 	t := struct {
-		CH     string `json:"ch"`
+		CH     string  `json:"ch"`
+		Rep    string  `json:"rep"`    // req请求的频道
+		Status string  `json:"status"` // req请求的结果
+		Data   mbpTick `json:"data"`   // mbp快照
 		Ticker struct {
+			// mbp增量深度的序号
+			SeqNum     int64 `json:"seqNum"`
+			PrevSeqNum int64 `json:"prevSeqNum"`
 			// ticker 数据
 			Amount float64 `json:"amount"`
 			Open   float64 `json:"open"`
@@ -35,17 +41,26 @@ func (c *Client) parse(msg []byte) {
 	//fmt.Println("huobipro: ", string(msg))
 	err := json.Unmarshal(msg, &t)
 	if err != nil {
-		log.Printf("json unmarshal %s\n", err.Error())
+		c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+		return
+	}
+	if t.Rep != "" {
+		// mbp快照 e.g "market.btcusdt.mbp.150"
+		if es := strings.Split(t.Rep, "."); len(es) >= 3 && es[2] == "mbp" {
+			base, quote := SplitSymbol(es[1])
+			c.parseSnapshot(global.TradeSymbol{Base: base, Quote: quote}, t.Status, t.Data, msg)
+		}
 		return
 	}
 	es := strings.Split(t.CH, ".")
 	if len(es) < 3 {
-		log.Println("huobipro ch error: ", es)
+		c.logger.Logln(core.Warn, "huobipro ch error: ", es)
 		return
 	}
 
 	// tick e.g "market.btcusdt.detail"
 	// depth e.g "market.btcusdt.depth.step0"
+	// mbp e.g "market.btcusdt.mbp.150"
 	// latetrade e.g "market.btcusdt.trade.detail"
 	base, quote := SplitSymbol(es[1])
 	key := global.TradeSymbol{Base: base, Quote: quote}
@@ -54,7 +69,7 @@ func (c *Client) parse(msg []byte) {
 		ch, ok := c.tick[key]
 		c.mutex.Unlock()
 		if !ok {
-			log.Printf("收到一个没有找到对应的消息 %+v %s\n", key, string(msg))
+			c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
 			return
 		}
 		v := t.Ticker.Close - t.Ticker.Open
@@ -68,13 +83,18 @@ func (c *Client) parse(msg []byte) {
 			LowPrice:           t.Ticker.Low,
 			Volume:             t.Ticker.Vol,
 		}
-		ch <- ret
+		c.config.ObserveMessage("huobi", "ticker:"+key.Base+key.Quote)
+		select {
+		case ch <- ret:
+		default:
+			c.config.ObserveDrop("huobi", "ticker:"+key.Base+key.Quote)
+		}
 	} else if es[2] == "depth" {
 		c.mutex.Lock()
 		ch, ok := c.depth[key]
 		c.mutex.Unlock()
 		if !ok {
-			log.Printf("收到一个没有找到对应的消息 %+v %s\n", key, string(msg))
+			c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
 			return
 		}
 		ret := global.Depth{
@@ -111,13 +131,25 @@ func (c *Client) parse(msg []byte) {
 			ret.Bids = append(ret.Bids, global.DepthPair{Price: t.Ticker.Bids[i][0],
 				Size: t.Ticker.Bids[i][1]})
 		}
-		ch <- ret
+		c.config.ObserveMessage("huobi", "depth:"+key.Base+key.Quote)
+		select {
+		case ch <- ret:
+		default:
+			c.config.ObserveDrop("huobi", "depth:"+key.Base+key.Quote)
+		}
+	} else if es[2] == "mbp" {
+		c.parseMBP(key, mbpTick{
+			SeqNum:     t.Ticker.SeqNum,
+			PrevSeqNum: t.Ticker.PrevSeqNum,
+			Asks:       t.Ticker.Asks,
+			Bids:       t.Ticker.Bids,
+		}, msg)
 	} else if es[2] == "trade" {
 		c.mutex.Lock()
 		ch, ok := c.latetrade[key]
 		c.mutex.Unlock()
 		if !ok {
-			log.Printf("收到一个没有找到对应的消息 %+v %s\n", key, string(msg))
+			c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
 			return
 		}
 		for _, d := range t.Ticker.Data {
@@ -132,7 +164,12 @@ func (c *Client) parse(msg []byte) {
 				Dircetion: d.Direction,
 				Total:     d.Price * d.Amount,
 			}
-			ch <- lt
+			c.config.ObserveMessage("huobi", "trade:"+key.Base+key.Quote)
+			select {
+			case ch <- lt:
+			default:
+				c.config.ObserveDrop("huobi", "trade:"+key.Base+key.Quote)
+			}
 		}
 	}
 }
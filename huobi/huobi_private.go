@@ -0,0 +1,391 @@
+package huobi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
+	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
+)
+
+// private 私有websocket(/ws/v2)的连接状态，与行情连接相互独立
+type private struct {
+	connMu  sync.Mutex // 串行建立连接，避免首次连接和断线重连同时进行
+	mutex   sync.Mutex
+	sock    *websocket.Conn
+	orders  map[string]chan global.OrderUpdate // key为订阅的频道
+	balance chan global.BalanceUpdate
+	account int64 // 只推送该账户的资金变动
+}
+
+// v2Message /ws/v2 的请求、响应和推送
+type v2Message struct {
+	Action  string          `json:"action"`
+	Code    int             `json:"code"`
+	Ch      string          `json:"ch"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// SubOrderUpdate 订阅订单变动(orders#${symbol})，包括下单、成交和撤单
+// sreq为空时订阅所有交易对
+func (c *Client) SubOrderUpdate(sreq global.TradeSymbol) (chan global.OrderUpdate, error) {
+	return c.subOrders("orders#" + v2Symbol(sreq))
+}
+
+// SubTradeClearing 订阅成交明细(trade.clearing#${symbol}#0)，每笔成交推送一次，包含手续费
+// sreq为空时订阅所有交易对
+func (c *Client) SubTradeClearing(sreq global.TradeSymbol) (chan global.OrderUpdate, error) {
+	return c.subOrders("trade.clearing#" + v2Symbol(sreq) + "#0")
+}
+
+// SubBalanceUpdate 订阅config.WithAccount设置的账户(默认现货账户)的资金变动(accounts.update#2)
+func (c *Client) SubBalanceUpdate() (chan global.BalanceUpdate, error) {
+	id, err := c.accountID(c.account())
+	if err != nil {
+		return nil, err
+	}
+	p, err := c.privateConnect()
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.balance != nil {
+		return p.balance, nil
+	}
+	if p.sock == nil {
+		return nil, errPrivateDisconnected
+	}
+	if err := c.v2Sub(p.sock, "accounts.update#2"); err != nil {
+		return nil, err
+	}
+	p.account = id
+	p.balance = make(chan global.BalanceUpdate, 100)
+	return p.balance, nil
+}
+
+func (c *Client) subOrders(ch string) (chan global.OrderUpdate, error) {
+	p, err := c.privateConnect()
+	if err != nil {
+		return nil, err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if out, ok := p.orders[ch]; ok {
+		return out, nil
+	}
+	if p.sock == nil {
+		return nil, errPrivateDisconnected
+	}
+	if err := c.v2Sub(p.sock, ch); err != nil {
+		return nil, err
+	}
+	out := make(chan global.OrderUpdate, 100)
+	p.orders[ch] = out
+	return out, nil
+}
+
+func v2Symbol(sreq global.TradeSymbol) string {
+	if sreq.Base == "" && sreq.Quote == "" {
+		return "*"
+	}
+	return strings.ToLower(sreq.Base + sreq.Quote)
+}
+
+// privateConnect 返回已鉴权的私有连接，尚未连接时立即连接
+// 连接或鉴权失败时返回错误，下次调用会重新尝试
+func (c *Client) privateConnect() (*private, error) {
+	p := &c.private
+	if err := c.v2Connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// v2Connect 建立并鉴权私有连接，已经连接时直接返回
+func (c *Client) v2Connect() error {
+	p := &c.private
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+	p.mutex.Lock()
+	if p.orders == nil {
+		p.orders = make(map[string]chan global.OrderUpdate)
+	}
+	connected := p.sock != nil
+	p.mutex.Unlock()
+	if connected {
+		return nil
+	}
+
+	u := url.URL{Scheme: "wss", Host: *c.config.WSSHost, Path: "/ws/v2"}
+	c.logger.Logf(core.Info, "huobi 连接 %s 中... ", u.String())
+	conn, resp, err := c.config.DialWSS(u.String(), nil)
+	if err != nil {
+		c.logger.Logf(core.Info, "连接: 失败")
+		return err
+	}
+	// 鉴权签名包含域名，DialWSS可能换用了WSSHosts中的其他地址
+	host := u.Host
+	if resp != nil && resp.Request != nil {
+		host = resp.Request.URL.Host
+	}
+	if err = c.v2Auth(conn, host); err != nil {
+		conn.Close()
+		return err
+	}
+
+	p.mutex.Lock()
+	p.sock = conn
+	// 重连后重新订阅
+	for ch := range p.orders {
+		if err := c.v2Sub(conn, ch); err != nil {
+			c.logger.Logf(core.Warn, "订阅消息重放失败 %s %s", ch, err.Error())
+		}
+	}
+	if p.balance != nil {
+		if err := c.v2Sub(conn, "accounts.update#2"); err != nil {
+			c.logger.Logf(core.Warn, "订阅消息重放失败 accounts.update#2 %s", err.Error())
+		}
+	}
+	p.mutex.Unlock()
+
+	go func() {
+		for {
+			_, msg, err := c.config.ReadFrame("huobi", conn)
+			if err != nil {
+				c.logger.Logf(core.Warn, "huobipro private < %s > 断开连接，五秒后重连...", err.Error())
+				c.config.ObserveReconnect("huobi", "private")
+				p.mutex.Lock()
+				p.sock = nil
+				p.mutex.Unlock()
+				go c.v2Reconnect()
+				return
+			}
+			c.v2Parse(conn, msg)
+		}
+	}()
+	return nil
+}
+
+func (c *Client) v2Reconnect() {
+	for {
+		time.Sleep(5 * time.Second)
+		err := c.v2Connect()
+		if err == nil {
+			return
+		}
+		if _, ok := err.(*authError); ok {
+			c.logger.Logf(core.Error, "huobipro private 鉴权失败，停止重连: %s", err.Error())
+			return
+		}
+	}
+}
+
+// errPrivateDisconnected 私有连接已断开，正在重连
+var errPrivateDisconnected = errors.New("huobipro private connection lost, reconnecting")
+
+type authError struct {
+	code int
+	msg  string
+}
+
+func (e *authError) Error() string {
+	return fmt.Sprintf("huobipro auth failed: %d %s", e.code, e.msg)
+}
+
+// v2Auth 发送鉴权请求并等待结果，签名方式与rest接口相同
+func (c *Client) v2Auth(conn *websocket.Conn, host string) error {
+	cred, s, err := c.config.Signing("huobi", signer.HMACSHA256Base64)
+	if err != nil {
+		return err
+	}
+	params := map[string]string{
+		"accessKey":        cred.APIKey,
+		"signatureMethod":  "HmacSHA256",
+		"signatureVersion": "2.1",
+		"timestamp":        c.clock.Now().UTC().Format("2006-01-02T15:04:05"),
+	}
+	sign, err := createSign(params, "GET", host, "/ws/v2", s)
+	if err != nil {
+		return err
+	}
+	params["authType"] = "api"
+	params["signature"] = sign
+	req := map[string]interface{}{"action": "req", "ch": "auth", "params": params}
+
+	c.private.mutex.Lock()
+	err = c.config.WriteJSONFrame("huobi", conn, req)
+	c.private.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+	for {
+		_, msg, err := c.config.ReadFrame("huobi", conn)
+		if err != nil {
+			return err
+		}
+		var m v2Message
+		if err := json.Unmarshal(msg, &m); err != nil {
+			return err
+		}
+		if m.Action == "ping" {
+			c.v2Pong(conn, m.Data)
+			continue
+		}
+		if m.Action != "req" || m.Ch != "auth" {
+			continue
+		}
+		if m.Code != 200 {
+			return &authError{code: m.Code, msg: m.Message}
+		}
+		return nil
+	}
+}
+
+// v2Sub 发送订阅请求，调用方需持有private.mutex
+func (c *Client) v2Sub(conn *websocket.Conn, ch string) error {
+	return c.config.WriteJSONFrame("huobi", conn, map[string]string{"action": "sub", "ch": ch})
+}
+
+func (c *Client) v2Pong(conn *websocket.Conn, data json.RawMessage) {
+	c.private.mutex.Lock()
+	defer c.private.mutex.Unlock()
+	c.config.WriteJSONFrame("huobi", conn, map[string]interface{}{"action": "pong", "data": data})
+}
+
+func (c *Client) v2Parse(conn *websocket.Conn, msg []byte) {
+	var m v2Message
+	if err := json.Unmarshal(msg, &m); err != nil {
+		c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+		return
+	}
+	switch m.Action {
+	case "ping":
+		c.v2Pong(conn, m.Data)
+		return
+	case "sub":
+		if m.Code != 200 {
+			c.logger.Logf(core.Warn, "huobipro 订阅 %s 失败: %d %s", m.Ch, m.Code, m.Message)
+		}
+		return
+	case "push":
+	default:
+		return
+	}
+	c.config.ObserveMessage("huobi", "private")
+
+	p := &c.private
+	if strings.HasPrefix(m.Ch, "accounts.update#") {
+		var a AccountPush
+		if err := json.Unmarshal(m.Data, &a); err != nil {
+			c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+			return
+		}
+		p.mutex.Lock()
+		ch, account := p.balance, p.account
+		p.mutex.Unlock()
+		if ch == nil || a.AccountID != account {
+			return
+		}
+		balance, _ := strconv.ParseFloat(a.Balance, 64)
+		available, _ := strconv.ParseFloat(a.Available, 64)
+		u := global.BalanceUpdate{
+			Base:      strings.ToUpper(a.Currency),
+			Available: available,
+			Frozen:    balance - available,
+			Timestamp: a.ChangeTime,
+		}
+		select {
+		case ch <- u:
+		default:
+			c.config.ObserveDrop("huobi", "private")
+		}
+		return
+	}
+
+	p.mutex.Lock()
+	ch, ok := p.orders[m.Ch]
+	p.mutex.Unlock()
+	if !ok {
+		c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %s", string(msg))
+		return
+	}
+	var o OrderPush
+	if err := json.Unmarshal(m.Data, &o); err != nil {
+		c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+		return
+	}
+	select {
+	case ch <- o.toUpdate():
+	default:
+		c.config.ObserveDrop("huobi", "private")
+	}
+}
+
+// toUpdate 转换为统一的订单变动
+func (o *OrderPush) toUpdate() global.OrderUpdate {
+	base, quote := SplitSymbol(o.Symbol)
+	u := global.OrderUpdate{
+		Base:          base,
+		Quote:         quote,
+		OrderNo:       strconv.FormatInt(o.OrderID, 10),
+		ClientOrderID: o.ClientOrderID,
+		FeeAsset:      strings.ToUpper(o.FeeCurrency),
+		Status:        orderStatus(o.OrderStatus),
+	}
+	u.Price, _ = strconv.ParseFloat(o.OrderPrice, 64)
+	u.Num, _ = strconv.ParseFloat(o.OrderSize, 64)
+	u.TradePrice, _ = strconv.ParseFloat(o.TradePrice, 64)
+	u.TradeNum, _ = strconv.ParseFloat(o.TradeVolume, 64)
+	u.Fee, _ = strconv.ParseFloat(o.TransactFee, 64)
+	t := o.OrderType
+	if t == "" {
+		t = o.ClearingType
+	}
+	if strings.HasPrefix(t, "sell") {
+		u.Direction = 1
+	}
+	if strings.Contains(t, "market") {
+		u.Type = 1
+		if u.Direction == 0 {
+			u.Num, _ = strconv.ParseFloat(o.OrderValue, 64)
+		}
+	}
+	switch {
+	case o.TradeTime != 0:
+		u.Timestamp = o.TradeTime
+	case o.LastActTime != 0:
+		u.Timestamp = o.LastActTime
+	default:
+		u.Timestamp = o.CreateTime
+	}
+	return u
+}
+
+// orderStatus 将火币的订单状态转换为global中的状态
+func orderStatus(s string) int {
+	switch s {
+	case "created", "submitted":
+		return global.HANGING
+	case "partial-filled":
+		return global.HALFTRADE
+	case "filled":
+		return global.COMPLETETRADE
+	case "canceling":
+		return global.CANCELING
+	case "canceled", "partial-canceled":
+		return global.CANCELED
+	}
+	return global.FAILED
+}
@@ -41,7 +41,7 @@ func (c *Client) GetDepth(sreq global.TradeSymbol) (global.Depth, error) {
 	symbol := strings.ToLower(sreq.Base + sreq.Quote)
 	in := map[string]string{}
 	in["symbol"] = symbol
-	in["type"] = "step0"
+	in["type"] = *c.config.DepthStep
 
 	r := struct {
 		Status string `json:"status"`
@@ -51,7 +51,7 @@ func (c *Client) GetDepth(sreq global.TradeSymbol) (global.Depth, error) {
 		} `json:"tick"`
 		Errmsg string `json:"err-msg"`
 	}{}
-	err := c.doHTTP("GET", "/market/depth", in, &r)
+	err := c.doHTTPRetry("GET", "/market/depth", in, &r)
 	if err != nil {
 		return global.Depth{}, err
 	}
@@ -98,10 +98,21 @@ func (c *Client) GetDepth(sreq global.TradeSymbol) (global.Depth, error) {
 
 // GetKline websocket 查询kline
 func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
-	conn, err := c.connect()
+	var ik []global.Kline
+	err := c.config.RetryDo("huobi", func() error {
+		var e error
+		ik, e = c.getKline(req)
+		return e
+	})
+	return ik, err
+}
+
+func (c *Client) getKline(req global.KlineReq) ([]global.Kline, error) {
+	conn, err := c.connect("/ws")
 	if err != nil {
 		return nil, err
 	}
+	defer conn.Close()
 	period := req.Period
 	if strings.Contains(period, "m") {
 		period = period + "in"
@@ -122,12 +133,12 @@ func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
 	}{Topic: topic, ID: c.generateClientID()}
 
 	c.mutex.Lock()
-	err = conn.WriteJSON(kreq)
+	err = c.config.WriteJSONFrame("huobi", conn, kreq)
 	c.mutex.Unlock()
 	if err != nil {
 		return nil, err
 	}
-	_, msg, err := conn.ReadMessage()
+	_, msg, err := c.config.ReadFrame("huobi", conn)
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +40,8 @@ type InsertOrderReq struct {
 	Source    string `json:"source"`          // 订单来源, api: API调用, margin-api: 借贷资产交易
 	Symbol    string `json:"symbol"`          // 交易对, btcusdt, bccbtc......
 	OrderType string `json:"type"`            // 订单类型, buy-market: 市价买, sell-market: 市价卖, buy-limit: 限价买, sell-limit: 限价卖
+
+	ClientOrderID string `json:"client-order-id,omitempty"` // 客户端自定义订单号
 }
 
 // OrderDetail ...
@@ -73,3 +75,35 @@ type MatchDetail struct {
 	Symbol      string `json:"symbol"`        //交易对	btcusdt, bchbtc, rcneth ...
 	OrderType   string `json:"type"`          //订单类型	buy-market：市价买, sell-market：市价卖, buy-limit：限价买, sell-limit：限价卖
 }
+
+// OrderPush 私有频道orders#${symbol}和trade.clearing#${symbol}#${mode}推送的订单数据
+type OrderPush struct {
+	EventType     string `json:"eventType"` // creation、trade、cancellation
+	Symbol        string `json:"symbol"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	OrderType     string `json:"type"`        // orders频道的订单类型，如buy-limit
+	ClearingType  string `json:"orderType"`   // trade.clearing频道的订单类型
+	OrderPrice    string `json:"orderPrice"`  // 委托价格
+	OrderSize     string `json:"orderSize"`   // 委托数量
+	OrderValue    string `json:"orderValue"`  // 市价买单的委托金额
+	OrderStatus   string `json:"orderStatus"` // submitted、partial-filled、filled、canceled、rejected
+	TradePrice    string `json:"tradePrice"`
+	TradeVolume   string `json:"tradeVolume"`
+	TransactFee   string `json:"transactFee"`
+	FeeCurrency   string `json:"feeCurrency"`
+	CreateTime    int64  `json:"orderCreateTime"`
+	TradeTime     int64  `json:"tradeTime"`
+	LastActTime   int64  `json:"lastActTime"`
+}
+
+// AccountPush 私有频道accounts.update#${mode}推送的账户变动
+type AccountPush struct {
+	Currency    string `json:"currency"`
+	AccountID   int64  `json:"accountId"`
+	Balance     string `json:"balance"`   // 总额
+	Available   string `json:"available"` // 可用
+	ChangeType  string `json:"changeType"`
+	AccountType string `json:"accountType"`
+	ChangeTime  int64  `json:"changeTime"`
+}
@@ -7,20 +7,22 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
 )
 
 // SubDepth 查询市场深度数据
-// type 可选值：{ step0, step1, step2, step3, step4, step5 } （合并深度0-5）；
-// step0时，不合并深度
+// 默认订阅全量深度，合并档位由config.WithDepthStep设置，
+// 可选值：{ step0, step1, step2, step3, step4, step5 } （合并深度0-5）；step0时，不合并深度
+// config.WithDepthLevels设置档数(5、20、150)后改为订阅mbp增量深度并在本地维护，
+// 150档只能通过/feed订阅，此时该Client只能订阅深度
 func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 	c.once.Do(func() { c.wsConnect() })
 	if c.sock == nil {
@@ -28,9 +30,7 @@ func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 	}
 	sreq.Base = strings.ToUpper(sreq.Base)
 	sreq.Quote = strings.ToUpper(sreq.Quote)
-	symbol := strings.ToLower(sreq.Base + sreq.Quote)
-
-	topic := fmt.Sprintf("market.%s.depth.%s", symbol, "step0")
+	topic := c.depthTopic(sreq)
 	req := struct {
 		Topic string `json:"sub"`
 		ID    string `json:"id"`
@@ -38,13 +38,19 @@ func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	err := c.sock.WriteJSON(req)
+	err := c.config.WriteJSONFrame("huobi", c.sock, req)
 	if err != nil {
 		return nil, err
 	}
 
 	ch := make(chan global.Depth, 100)
 	c.depth[sreq] = ch
+	if c.depthLevels() > 0 {
+		c.books[sreq] = newMBPBook()
+		if err := c.requestSnapshot(sreq); err != nil {
+			return nil, err
+		}
+	}
 
 	// 直接返回
 	return ch, nil
@@ -52,6 +58,9 @@ func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 
 // SubLateTrade 查询交易详细数据
 func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, error) {
+	if c.marketPath() == "/feed" {
+		return nil, errFeedOnly
+	}
 	c.once.Do(func() { c.wsConnect() })
 	if c.sock == nil {
 		return nil, errors.New("connect failed")
@@ -68,7 +77,7 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	err := c.sock.WriteJSON(req)
+	err := c.config.WriteJSONFrame("huobi", c.sock, req)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +89,9 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 
 // SubTicker ...
 func (c *Client) SubTicker(sreq global.TradeSymbol) (chan global.Ticker, error) {
+	if c.marketPath() == "/feed" {
+		return nil, errFeedOnly
+	}
 	c.once.Do(func() { c.wsConnect() })
 	if c.sock == nil {
 		return nil, errors.New("connect failed")
@@ -96,7 +108,7 @@ func (c *Client) SubTicker(sreq global.TradeSymbol) (chan global.Ticker, error)
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	err := c.sock.WriteJSON(req)
+	err := c.config.WriteJSONFrame("huobi", c.sock, req)
 	if err != nil {
 		return nil, err
 	}
@@ -106,17 +118,18 @@ func (c *Client) SubTicker(sreq global.TradeSymbol) (chan global.Ticker, error)
 	return ch, nil
 }
 
-func (c *Client) connect() (*websocket.Conn, error) {
-	u := url.URL{Scheme: "wss", Host: *c.config.WSSHost, Path: "/ws"}
-	log.Printf("huobi 连接 %s 中... ", u.String())
-	conn, _, err := c.config.WSSDialer.Dial(u.String(), nil)
-	log.Printf("连接: %s\n", utils.Ternary(err == nil, "成功", "失败").(string))
+// connect 连接行情websocket，path为/ws或/feed
+func (c *Client) connect(path string) (*websocket.Conn, error) {
+	u := url.URL{Scheme: "wss", Host: *c.config.WSSHost, Path: path}
+	c.logger.Logf(core.Info, "huobi 连接 %s 中... ", u.String())
+	conn, _, err := c.config.DialWSS(u.String(), nil)
+	c.logger.Logf(core.Info, "连接: %s", utils.Ternary(err == nil, "成功", "失败").(string))
 	return conn, err
 }
 
 func (c *Client) wsConnect() error {
 	c.sock = nil
-	conn, err := c.connect()
+	conn, err := c.connect(c.marketPath())
 	if err != nil {
 		c.sock = nil
 		return err
@@ -125,7 +138,7 @@ func (c *Client) wsConnect() error {
 
 	//在这儿进行订阅消息重放
 	if c.replay {
-		log.Printf("连接成功，进行消息重放\n")
+		c.logger.Logf(core.Info, "连接成功，进行消息重放")
 		for k := range c.tick {
 			symbol := strings.ToLower(k.Base + k.Quote)
 			topic := fmt.Sprintf("market.%s.detail", symbol)
@@ -134,26 +147,30 @@ func (c *Client) wsConnect() error {
 				ID    string `json:"id"`
 			}{topic, c.generateClientID()}
 			c.mutex.Lock()
-			err := c.sock.WriteJSON(req)
+			err := c.config.WriteJSONFrame("huobi", c.sock, req)
 			c.mutex.Unlock()
 			if err != nil {
-				log.Printf("订阅消息重放失败 %s %s\n", topic, err.Error())
+				c.logger.Logf(core.Warn, "订阅消息重放失败 %s %s", topic, err.Error())
 			}
 		}
 
 		//
 		for k := range c.depth {
-			symbol := strings.ToLower(k.Base + k.Quote)
-			topic := fmt.Sprintf("market.%s.depth.%s", symbol, "step0")
+			topic := c.depthTopic(k)
 			req := struct {
 				Topic string `json:"sub"`
 				ID    string `json:"id"`
 			}{topic, c.generateClientID()}
 			c.mutex.Lock()
-			err := c.sock.WriteJSON(req)
+			err := c.config.WriteJSONFrame("huobi", c.sock, req)
+			if b := c.books[k]; err == nil && b != nil {
+				// 断线期间的推送已经丢失，重新获取快照
+				b.reset()
+				err = c.requestSnapshot(k)
+			}
 			c.mutex.Unlock()
 			if err != nil {
-				log.Printf("订阅消息重放失败 %s %s\n", topic, err.Error())
+				c.logger.Logf(core.Warn, "订阅消息重放失败 %s %s", topic, err.Error())
 			}
 		}
 
@@ -166,10 +183,10 @@ func (c *Client) wsConnect() error {
 				ID    string `json:"id"`
 			}{topic, c.generateClientID()}
 			c.mutex.Lock()
-			err := c.sock.WriteJSON(req)
+			err := c.config.WriteJSONFrame("huobi", c.sock, req)
 			c.mutex.Unlock()
 			if err != nil {
-				log.Printf("订阅消息重放失败 %s %s\n", topic, err.Error())
+				c.logger.Logf(core.Warn, "订阅消息重放失败 %s %s", topic, err.Error())
 			}
 		}
 	}
@@ -179,7 +196,8 @@ func (c *Client) wsConnect() error {
 		for {
 			msg, err := c.readWSMessage(c.sock)
 			if err != nil {
-				log.Printf("huobipro < %s > 断开连接，五秒后重连...\n", err.Error())
+				c.logger.Logf(core.Warn, "huobipro < %s > 断开连接，五秒后重连...", err.Error())
+				c.config.ObserveReconnect("huobi", "market")
 				go func() {
 					time.Sleep(5 * time.Second)
 					c.wsConnect()
@@ -200,7 +218,7 @@ func (c *Client) wsConnect() error {
 }
 
 func (c *Client) readWSMessage(conn *websocket.Conn) ([]byte, error) {
-	_, msg, err := conn.ReadMessage()
+	_, msg, err := c.config.ReadFrame("huobi", conn)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +242,7 @@ func (c *Client) readWSMessage(conn *websocket.Conn) ([]byte, error) {
 		pong := struct {
 			Pong int64 `json:"pong"`
 		}{ping.Ping}
-		conn.WriteJSON(pong)
+		c.config.WriteJSONFrame("huobi", conn, pong)
 		//fmt.Printf("%+v\n", pong)
 		return nil, nil
 	}
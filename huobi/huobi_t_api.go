@@ -3,23 +3,25 @@ package huobi
 import (
 	"errors"
 	"fmt"
-	"log"
 	"strconv"
+	"strings"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
-	//"gitlab.mybcdn.com/golang/blockcoin/apidb"
+	"github.com/gotoxu/log/core"
 )
 
 // GetFund 查询指定账户的余额
-// @parm accountID GetAllAccountID函数返回的id
+// req.AccountID 为账户类型(spot、margin等)或GetAllAccountID返回的id，
+// 为空时使用config.WithAccount设置的账户，默认为现货账户
 func (c *Client) GetFund(req global.FundReq) ([]global.Fund, error) {
-	ids, e := c.GetAllAccountID()
+	account := req.AccountID
+	if account == "" {
+		account = c.account()
+	}
+	id, e := c.accountID(account)
 	if e != nil {
 		return nil, e
 	}
-	if len(ids) == 0 {
-		return nil, errors.New("huobipro no accountid")
-	}
 	r := struct {
 		Status string `json:"status"`
 		Data   struct {
@@ -29,8 +31,8 @@ func (c *Client) GetFund(req global.FundReq) ([]global.Fund, error) {
 		Errmsg string `json:"err-msg"`
 	}{}
 
-	path := fmt.Sprintf("/v1/account/accounts/%d/balance", ids[0].AccountID)
-	e = c.doHTTP("GET", path, nil, &r)
+	path := fmt.Sprintf("/v1/account/accounts/%d/balance", id)
+	e = c.doHTTPRetry("GET", path, nil, &r)
 	if e != nil {
 		return nil, e
 	}
@@ -47,8 +49,11 @@ func (c *Client) GetFund(req global.FundReq) ([]global.Fund, error) {
 			t.Available, _ = strconv.ParseFloat(bb.Amount, 64)
 		} else if bb.BType == "frozen" {
 			t.Frozen, _ = strconv.ParseFloat(bb.Amount, 64)
+		} else if bb.BType == "loan" || bb.BType == "interest" {
+			// 借贷账户的借款和利息不计入余额
+			continue
 		} else {
-			log.Println("火币账户资金类型错误")
+			c.logger.Logln(core.Warn, "火币账户资金类型错误")
 			return nil, errors.New("火币账户资金类型错误")
 		}
 		find := false
@@ -67,49 +72,152 @@ func (c *Client) GetFund(req global.FundReq) ([]global.Fund, error) {
 	return ir, nil
 }
 
-// InsertOrder 下单
-// @return string: orderNo
-// func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
-// 	ids, e := c.GetAllAccountID()
-// 	if e != nil {
-// 		return global.InsertRsp{}, e
-// 	}
-// 	if len(ids) == 0 {
-// 		return global.InsertRsp{}, errors.New("huobipro no accountid")
-// 	}
-
-// 	ireq := InsertOrderReq{
-// 		Source:    "api",
-// 		AccountID: strconv.FormatInt(ids[0].AccountID, 10),
-// 		Price:     strconv.FormatFloat(req.Price, 'f', -1, 64),
-// 		Amount:    strconv.FormatFloat(req.Num, 'f', -1, 64),
-// 		Symbol:    strings.ToLower(req.Base + req.Quote),
-// 	}
-// 	sd := "buy"
-// 	st := "limit"
-// 	if apidb.OrderDirection(req.Direction) == apidb.SELL {
-// 		sd = "sell"
-// 	}
-// 	if apidb.OrderType(req.Type) == apidb.MARKET {
-// 		st = "market"
-// 	}
-// 	ireq.OrderType = sd + "-" + st
-
-// 	mapParams := if2map(req)
-// 	r := struct {
-// 		Status string `json:"status"`
-// 		Errmsg string `json:"err-msg"`
-// 		Data   string `json:"data"`
-// 	}{}
-// 	e = c.doHTTP("POST", "/v1/hadax/order/orders/place", mapParams, &r)
-// 	if e != nil {
-// 		return global.InsertRsp{}, e
-// 	}
-// 	if r.Status != "ok" {
-// 		return global.InsertRsp{}, fmt.Errorf(r.Errmsg)
-// 	}
-// 	return global.InsertRsp{OrderNo: r.Data}, nil
-// }
+// InsertOrder 在config.WithAccount设置的账户(默认现货账户)下单
+// 市价买单的req.Num表示花费的计价币数量，其余情况表示买卖的币数量
+// 设置req.ClientOrderID后下单失败时会先确认订单是否已经生效，再按重试策略重试
+func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
+	account := c.account()
+	id, e := c.accountID(account)
+	if e != nil {
+		return global.InsertRsp{}, e
+	}
+
+	ireq := InsertOrderReq{
+		Source:        "api",
+		AccountID:     strconv.FormatInt(id, 10),
+		Amount:        strconv.FormatFloat(req.Num, 'f', -1, 64),
+		Symbol:        strings.ToLower(req.Base + req.Quote),
+		ClientOrderID: req.ClientOrderID,
+	}
+	if t, ok := c.accountType(id); ok && t != "spot" {
+		// 借贷账户的订单来源为margin-api、super-margin-api等
+		ireq.Source = t + "-api"
+	}
+	sd, st := "buy", "limit"
+	if req.Direction == 1 {
+		sd = "sell"
+	}
+	if req.Type == 1 {
+		st = "market"
+	} else {
+		ireq.Price = strconv.FormatFloat(req.Price, 'f', -1, 64)
+	}
+	ireq.OrderType = sd + "-" + st
+
+	r := struct {
+		Status string `json:"status"`
+		Errmsg string `json:"err-msg"`
+		Data   string `json:"data"`
+	}{}
+	place := func() error {
+		if e := c.doHTTP("POST", "/v1/order/orders/place", if2map(ireq), &r); e != nil {
+			return e
+		}
+		if r.Status != "ok" {
+			return errors.New(r.Errmsg)
+		}
+		return nil
+	}
+	// 只有带上客户端订单号时才能确认下单是否已经生效，才允许重试
+	var lookup func() (bool, error)
+	if req.ClientOrderID != "" {
+		lookup = func() (bool, error) {
+			return c.lookupClientOrder(req.ClientOrderID, &r.Data)
+		}
+	}
+	if e = c.config.RetryDoOrder("huobi", place, lookup); e != nil {
+		return global.InsertRsp{}, e
+	}
+	return global.InsertRsp{OrderNo: r.Data}, nil
+}
+
+// lookupClientOrder 通过客户端订单号确认订单是否已经存在，存在时将交易所订单号写入orderNo
+func (c *Client) lookupClientOrder(clientOrderID string, orderNo *string) (bool, error) {
+	r := struct {
+		Status  string      `json:"status"`
+		Errcode string      `json:"err-code"`
+		Errmsg  string      `json:"err-msg"`
+		Data    OrderDetail `json:"data"`
+	}{}
+	e := c.doHTTP("GET", "/v1/order/orders/getClientOrder",
+		map[string]string{"clientOrderId": clientOrderID}, &r)
+	if e != nil {
+		return false, e
+	}
+	if r.Status != "ok" {
+		if r.Errcode == "base-record-invalid" {
+			// 订单不存在
+			return false, nil
+		}
+		return false, errors.New(r.Errmsg)
+	}
+	*orderNo = strconv.FormatInt(r.Data.MatchNo, 10)
+	return true, nil
+}
+
+// account 返回config中设置的账户，默认为现货账户
+func (c *Client) account() string {
+	if c.config.Account != nil && *c.config.Account != "" {
+		return *c.config.Account
+	}
+	return "spot"
+}
+
+// accountID 将账户类型或账户id解析为账户id，同一类型有多个账户时需要直接指定id
+func (c *Client) accountID(account string) (int64, error) {
+	if id, e := strconv.ParseInt(account, 10, 64); e == nil {
+		return id, nil
+	}
+	accounts, e := c.loadAccounts()
+	if e != nil {
+		return 0, e
+	}
+	var ids []int64
+	for _, a := range accounts {
+		if a.AccountType == account {
+			ids = append(ids, a.AccountID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("huobipro no %s account", account)
+	case 1:
+		return ids[0], nil
+	}
+	return 0, fmt.Errorf("huobipro has %d %s accounts, account id required", len(ids), account)
+}
+
+// accountType 返回账户id对应的账户类型
+func (c *Client) accountType(id int64) (string, bool) {
+	accounts, e := c.loadAccounts()
+	if e != nil {
+		return "", false
+	}
+	for _, a := range accounts {
+		if a.AccountID == id {
+			return a.AccountType, true
+		}
+	}
+	return "", false
+}
+
+// loadAccounts 查询并缓存账户列表
+func (c *Client) loadAccounts() ([]Account, error) {
+	c.accountMu.Lock()
+	defer c.accountMu.Unlock()
+	if c.accounts != nil {
+		return c.accounts, nil
+	}
+	accounts, e := c.GetAllAccountID()
+	if e != nil {
+		return nil, e
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("huobipro no accountid")
+	}
+	c.accounts = accounts
+	return accounts, nil
+}
 
 // CancelOrder 撤销一个订单请求
 // 注意，返回OK表示撤单请求成功。订单是否撤销成功请调用订单查询接口查询该订单状态
@@ -138,7 +246,7 @@ func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 		Errmsg string      `json:"err-msg"`
 		Data   OrderDetail `json:"data"`
 	}{}
-	e := c.doHTTP("GET", path, nil, &r)
+	e := c.doHTTPRetry("GET", path, nil, &r)
 	if e != nil {
 		return global.StatusRsp{}, e
 	}
@@ -161,6 +269,6 @@ func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 		m.Status = global.CANCELED
 		m.StatusMsg = "已撤单"
 	}
-	fmt.Printf("huobipro order status %+v\n", or)
+	c.logger.Logf(core.Debug, "huobipro order status %+v", or)
 	return m, nil
 }
@@ -0,0 +1,400 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/binance"
+	"github.com/blockcdn-go/exchange-sdk-go/bitstamp"
+	"github.com/blockcdn-go/exchange-sdk-go/coinegg"
+	"github.com/blockcdn-go/exchange-sdk-go/coinex"
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/blockcdn-go/exchange-sdk-go/credential"
+	"github.com/blockcdn-go/exchange-sdk-go/gate"
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/huobi"
+	"github.com/blockcdn-go/exchange-sdk-go/okex"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
+	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/blockcdn-go/exchange-sdk-go/weex"
+	"github.com/blockcdn-go/exchange-sdk-go/zb"
+	"github.com/gotoxu/log/core"
+)
+
+// Constructor 使用合并好的配置创建交易所client
+type Constructor func(cfg *config.Config) interface{}
+
+var constructors = map[string]Constructor{
+	"binance":         func(c *config.Config) interface{} { return binance.NewClient(c) },
+	"binance-futures": func(c *config.Config) interface{} { return binance.NewFuturesClient(c) },
+	"bitstamp":        func(c *config.Config) interface{} { return bitstamp.NewClient(c) },
+	"coinegg":         func(c *config.Config) interface{} { return coinegg.NewClient(c) },
+	"coinex":          func(c *config.Config) interface{} { return coinex.NewClient(c) },
+	"gate":            func(c *config.Config) interface{} { return gate.NewClient(c) },
+	"huobi":           func(c *config.Config) interface{} { return huobi.NewClient(c) },
+	"okex":            func(c *config.Config) interface{} { return okex.NewClient(c) },
+	"weex":            func(c *config.Config) interface{} { return weex.NewClient(c) },
+	"zb":              func(c *config.Config) interface{} { return zb.NewClient(c) },
+}
+
+// Register 注册一种交易所类型，name已存在时覆盖，应在Load之前调用
+func Register(name string, fn Constructor) {
+	constructors[name] = fn
+}
+
+// Error 汇总配置文件中的所有错误，每条错误带有出错字段的路径
+type Error struct {
+	File     string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %d个错误:\n  %s", e.File, len(e.Problems), strings.Join(e.Problems, "\n  "))
+}
+
+// Exchanges 是Load的结果，key为配置文件中的名称
+type Exchanges struct {
+	Configs map[string]*config.Config
+	Clients map[string]interface{} // 类型由type决定，如*huobi.Client、binance.Service
+}
+
+// API 返回name对应的client，client没有实现global.APIif时返回错误
+func (e *Exchanges) API(name string) (global.APIif, error) {
+	c, ok := e.Clients[name]
+	if !ok {
+		return nil, fmt.Errorf("loader: 没有配置%s", name)
+	}
+	api, ok := c.(global.APIif)
+	if !ok {
+		return nil, fmt.Errorf("loader: %s(%T)没有实现global.APIif", name, c)
+	}
+	return api, nil
+}
+
+// WS 返回name对应的client，client没有实现global.WSif时返回错误
+func (e *Exchanges) WS(name string) (global.WSif, error) {
+	c, ok := e.Clients[name]
+	if !ok {
+		return nil, fmt.Errorf("loader: 没有配置%s", name)
+	}
+	ws, ok := c.(global.WSif)
+	if !ok {
+		return nil, fmt.Errorf("loader: %s(%T)没有实现global.WSif", name, c)
+	}
+	return ws, nil
+}
+
+// Load 读取json或yaml配置文件(扩展名为.yaml或.yml时按yaml解析)，检查后创建所有交易所的client
+// base中的配置(如Context、Metrics、Middlewares等无法写在文件中的值)会作为每个交易所的基础配置，可以为nil
+// 文件中的相对路径以配置文件所在目录为基准
+func Load(path string, base *config.Config) (*Exchanges, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	return parse(path, data, ext == ".yaml" || ext == ".yml", filepath.Dir(path), base)
+}
+
+// Parse 解析配置内容，相对路径以当前目录为基准
+func Parse(data []byte, yaml bool, base *config.Config) (*Exchanges, error) {
+	return parse("config", data, yaml, ".", base)
+}
+
+func parse(name string, data []byte, yaml bool, dir string, base *config.Config) (*Exchanges, error) {
+	if yaml {
+		var err error
+		if data, err = utils.YAMLToJSONFor(data, File{}); err != nil {
+			return nil, &Error{File: name, Problems: []string{err.Error()}}
+		}
+	}
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &Error{File: name, Problems: []string{err.Error()}}
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, &Error{File: name, Problems: []string{err.Error()}}
+	}
+
+	b := &builder{dir: dir, providers: map[string]config.CredentialProvider{}}
+	checkFields(raw, reflect.TypeOf(File{}), "", &b.problems)
+	exs := b.build(&f, base)
+	if len(b.problems) > 0 {
+		sort.Strings(b.problems)
+		return nil, &Error{File: name, Problems: b.problems}
+	}
+	return exs, nil
+}
+
+type builder struct {
+	dir       string
+	problems  problems
+	providers map[string]config.CredentialProvider
+}
+
+func (b *builder) build(f *File, base *config.Config) *Exchanges {
+	exs := &Exchanges{Configs: map[string]*config.Config{}, Clients: map[string]interface{}{}}
+	if len(f.Exchanges) == 0 {
+		b.problems.add("exchanges", "至少需要配置一个交易所")
+	}
+	if f.Defaults.Type != "" {
+		b.problems.add("defaults.type", "不能在defaults中设置")
+	}
+	var logger core.Logger
+	if f.Logging != nil {
+		var err error
+		if logger, err = newLogger(f.Logging); err != nil {
+			b.problems.add("logging", "%s", err.Error())
+		}
+	}
+
+	ctors := map[string]Constructor{}
+	for name, ex := range f.Exchanges {
+		path := "exchanges." + name
+		ex = ex.withDefaults(f.Defaults)
+		if ex.Type == "" {
+			ex.Type = name
+		}
+		ctor, ok := constructors[ex.Type]
+		if !ok {
+			b.problems.add(path+".type", "不支持的交易所类型%q", ex.Type)
+			continue
+		}
+		ctors[name] = ctor
+		cfg := b.config(name, path, &ex)
+		if logger != nil {
+			cfg.WithLogger(logger)
+		}
+		merged := &config.Config{}
+		merged.MergeIn(base, cfg)
+		exs.Configs[name] = merged
+	}
+	if len(b.problems) > 0 {
+		return nil
+	}
+	for name, cfg := range exs.Configs {
+		exs.Clients[name] = ctors[name](cfg)
+	}
+	return exs
+}
+
+// config 将ex转换为config.Config，错误记录在b.problems中
+func (b *builder) config(name, path string, ex *Exchange) *config.Config {
+	cfg := &config.Config{}
+	switch ex.Environment {
+	case "", "production":
+	case "testnet":
+		cfg.WithEnvironment(config.Testnet)
+	default:
+		b.problems.add(path+".environment", "只能是production或testnet")
+	}
+	if ex.RESTHost != "" {
+		b.checkHost(path+".rest_host", ex.RESTHost)
+		cfg.WithRESTHost(ex.RESTHost)
+	}
+	if ex.WSSHost != "" {
+		b.checkHost(path+".wss_host", ex.WSSHost)
+		cfg.WithWSSHost(ex.WSSHost)
+	}
+	if len(ex.RESTHosts) != 0 {
+		if ex.RESTHost != "" {
+			b.problems.add(path+".rest_hosts", "不能与rest_host同时设置")
+		}
+		for i, h := range ex.RESTHosts {
+			b.checkHost(fmt.Sprintf("%s.rest_hosts[%d]", path, i), h)
+		}
+		cfg.WithRESTHosts(ex.RESTHosts...)
+	}
+	if len(ex.WSSHosts) != 0 {
+		if ex.WSSHost != "" {
+			b.problems.add(path+".wss_hosts", "不能与wss_host同时设置")
+		}
+		for i, h := range ex.WSSHosts {
+			b.checkHost(fmt.Sprintf("%s.wss_hosts[%d]", path, i), h)
+		}
+		cfg.WithWSSHosts(ex.WSSHosts...)
+	}
+	if d, ok := b.duration(path+".health_check", ex.HealthCheck); ok {
+		cfg.WithHealthCheck(d)
+	}
+	if ex.UseSSL != nil {
+		cfg.WithUseSSL(*ex.UseSSL)
+	}
+	if ex.DryRun != nil {
+		cfg.WithDryRun(*ex.DryRun)
+	}
+	if ex.Account != "" {
+		cfg.WithAccount(ex.Account)
+	}
+	if ex.Proxy != "" {
+		u, err := url.Parse(ex.Proxy)
+		if err != nil || u.Host == "" {
+			b.problems.add(path+".proxy", "无效的代理地址%q", ex.Proxy)
+		} else if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+			b.problems.add(path+".proxy", "只支持http、https和socks5代理")
+		} else {
+			cfg.WithProxy(u)
+		}
+	}
+	if d, ok := b.duration(path+".timeout", ex.Timeout); ok {
+		cfg.WithTimeout(d)
+	}
+	if d, ok := b.duration(path+".ping_interval", ex.PingInterval); ok {
+		cfg.WithPingDuration(d)
+	}
+	if d, ok := b.duration(path+".time_sync", ex.TimeSync); ok {
+		cfg.WithTimeSync(d)
+	}
+	if ex.NonceFile != "" {
+		cfg.WithNonceFile(b.abs(ex.NonceFile))
+	}
+	if r := ex.Retry; r != nil {
+		if r.MaxRetries < 0 {
+			b.problems.add(path+".retry.max_retries", "不能小于0")
+		}
+		p := config.DefaultRetryPolicy()
+		p.MaxRetries = r.MaxRetries
+		if d, ok := b.duration(path+".retry.min_backoff", r.MinBackoff); ok {
+			p.MinBackoff = d
+		}
+		if d, ok := b.duration(path+".retry.max_backoff", r.MaxBackoff); ok {
+			p.MaxBackoff = d
+		}
+		cfg.WithRetryPolicy(p)
+	}
+	if r := ex.RateLimit; r != nil {
+		per := time.Second
+		if d, ok := b.duration(path+".rate_limit.per", r.Per); ok {
+			per = d
+		}
+		if r.Requests <= 0 {
+			b.problems.add(path+".rate_limit.requests", "必须大于0")
+		} else {
+			cfg.WithRateLimit(config.NewRateLimit(r.Requests, per, r.Burst))
+		}
+	}
+	if ex.Credentials == nil {
+		if ex.Signer != nil {
+			b.problems.add(path+".credentials", "使用signer时仍需要配置apikey的来源")
+		}
+	} else {
+		b.credentials(path+".credentials", ex.Credentials, cfg)
+	}
+	if s := ex.Signer; s != nil {
+		if s.Remote == "" {
+			b.problems.add(path+".signer.remote", "不能为空")
+		}
+		keyID := s.KeyID
+		if keyID == "" {
+			keyID = name
+		}
+		r := signer.NewRemote(b.abs(s.Remote), keyID)
+		if d, ok := b.duration(path+".signer.timeout", s.Timeout); ok {
+			r.Timeout = d
+		}
+		cfg.WithSigner(r)
+	}
+	return cfg
+}
+
+func (b *builder) credentials(path string, c *Credentials, cfg *config.Config) {
+	n := 0
+	for _, s := range []string{c.APIKey, c.Env, c.File, c.Keystore} {
+		if s != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		b.problems.add(path, "apikey、env、file和keystore必须且只能设置一个")
+		return
+	}
+	if c.Secret != "" && c.APIKey == "" {
+		b.problems.add(path+".secret", "只能与apikey一起使用")
+	}
+	if c.Passphrase != "" && c.APIKey == "" {
+		b.problems.add(path+".passphrase", "只能与apikey一起使用")
+	}
+	if c.PassphraseEnv != "" && c.Keystore == "" {
+		b.problems.add(path+".passphrase_env", "只能与keystore一起使用")
+	}
+
+	switch {
+	case c.APIKey != "":
+		cfg.WithAPIKey(c.APIKey).WithSecret(c.Secret)
+		if c.Passphrase != "" {
+			cfg.WithPassphrase(c.Passphrase)
+		}
+	case c.Env != "":
+		cfg.WithCredentialProvider(credential.NewEnv(c.Env))
+	case c.File != "":
+		file := b.abs(c.File)
+		if p, ok := b.providers[file]; ok {
+			cfg.WithCredentialProvider(p)
+			return
+		}
+		p, err := credential.NewFile(file)
+		if err != nil {
+			b.problems.add(path+".file", "%s", err.Error())
+			return
+		}
+		b.providers[file] = p
+		cfg.WithCredentialProvider(p)
+	case c.Keystore != "":
+		file := b.abs(c.Keystore)
+		if p, ok := b.providers[file]; ok {
+			cfg.WithCredentialProvider(p)
+			return
+		}
+		if c.PassphraseEnv == "" {
+			b.problems.add(path+".passphrase_env", "使用keystore时不能为空")
+			return
+		}
+		pass := os.Getenv(c.PassphraseEnv)
+		if pass == "" {
+			b.problems.add(path+".passphrase_env", "环境变量%s未设置", c.PassphraseEnv)
+			return
+		}
+		p, err := credential.NewKeystore(file, pass)
+		if err != nil {
+			b.problems.add(path+".keystore", "%s", err.Error())
+			return
+		}
+		b.providers[file] = p
+		cfg.WithCredentialProvider(p)
+	}
+}
+
+func (b *builder) checkHost(path, host string) {
+	if strings.Contains(host, "://") || strings.Contains(host, "/") {
+		b.problems.add(path, "只需填写域名和端口，不能包含协议或路径: %q", host)
+	}
+}
+
+// duration 返回d是否已设置，无效的值记录为错误
+func (b *builder) duration(path string, d Duration) (time.Duration, bool) {
+	if d == "" {
+		return 0, false
+	}
+	v, err := d.parse()
+	if err != nil {
+		b.problems.add(path, "%s", err.Error())
+		return 0, false
+	}
+	return v, v > 0
+}
+
+func (b *builder) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(b.dir, path)
+}
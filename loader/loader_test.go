@@ -0,0 +1,71 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseYAMLNumericValues(t *testing.T) {
+	data := []byte(`
+defaults:
+  timeout: 5
+  retry:
+    max_retries: 5
+exchanges:
+  huobi:
+    credentials:
+      apikey: 123456
+      secret: 007
+    rate_limit:
+      requests: 10
+      per: 1s
+`)
+	exs, err := Parse(data, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := exs.Configs["huobi"]
+	if cfg == nil {
+		t.Fatal("huobi not configured")
+	}
+	if *cfg.APIKey != "123456" || *cfg.Secret != "007" {
+		t.Errorf("apikey %q secret %q", *cfg.APIKey, *cfg.Secret)
+	}
+	if cfg.Timeout == nil || *cfg.Timeout != 5*time.Second {
+		t.Errorf("timeout %v", cfg.Timeout)
+	}
+	if cfg.Retry == nil || cfg.Retry.MaxRetries != 5 {
+		t.Errorf("retry %+v", cfg.Retry)
+	}
+}
+
+func TestParseInlinePassphrase(t *testing.T) {
+	data := []byte(`
+exchanges:
+  okex:
+    credentials:
+      apikey: key
+      secret: secret
+      passphrase: 123456
+`)
+	exs, err := Parse(data, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := exs.Configs["okex"]
+	if cfg == nil || cfg.Passphrase == nil || *cfg.Passphrase != "123456" {
+		t.Fatalf("passphrase not set: %+v", cfg)
+	}
+
+	data = []byte(`
+exchanges:
+  okex:
+    credentials:
+      env: OKEX_
+      passphrase: secret
+`)
+	if _, err := Parse(data, true, nil); err == nil || !strings.Contains(err.Error(), "credentials.passphrase") {
+		t.Errorf("passphrase without apikey: %v", err)
+	}
+}
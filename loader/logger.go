@@ -0,0 +1,103 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gotoxu/log/core"
+)
+
+var levelNames = map[string]core.Level{
+	"debug": core.Debug,
+	"info":  core.Info,
+	"warn":  core.Warn,
+	"error": core.Error,
+}
+
+// stdLogger 基于标准库log的core.Logger实现，字段以key=value的形式附加在消息后
+type stdLogger struct {
+	out    *log.Logger
+	file   *os.File
+	level  core.Level
+	fields string
+}
+
+func newLogger(l *Logging) (core.Logger, error) {
+	level, ok := levelNames[strings.ToLower(l.Level)]
+	if l.Level == "" {
+		level, ok = core.Info, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("未知的日志等级 %q", l.Level)
+	}
+	var w io.Writer
+	var f *os.File
+	switch l.Output {
+	case "", "stderr":
+		w = os.Stderr
+	case "stdout":
+		w = os.Stdout
+	default:
+		var err error
+		if f, err = os.OpenFile(l.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return nil, err
+		}
+		w = f
+	}
+	return &stdLogger{out: log.New(w, "", log.LstdFlags|log.Lmicroseconds), file: f, level: level}, nil
+}
+
+func (l *stdLogger) output(level core.Level, msg string) {
+	if level < l.level {
+		return
+	}
+	name := "FATAL"
+	switch level {
+	case core.Debug:
+		name = "DEBUG"
+	case core.Info:
+		name = "INFO"
+	case core.Warn:
+		name = "WARN"
+	case core.Error:
+		name = "ERROR"
+	case core.Panic:
+		name = "PANIC"
+	}
+	l.out.Output(3, name+" "+msg+l.fields)
+	switch level {
+	case core.Panic:
+		panic(msg)
+	case core.Fatal:
+		l.Sync()
+		os.Exit(1)
+	}
+}
+
+func (l *stdLogger) Log(level core.Level, v ...interface{}) {
+	l.output(level, fmt.Sprint(v...))
+}
+
+func (l *stdLogger) Logf(level core.Level, format string, v ...interface{}) {
+	l.output(level, fmt.Sprintf(format, v...))
+}
+
+func (l *stdLogger) Logln(level core.Level, v ...interface{}) {
+	l.output(level, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+func (l *stdLogger) With(key string, value interface{}) core.Logger {
+	c := *l
+	c.fields += fmt.Sprintf(" %s=%v", key, value)
+	return &c
+}
+
+func (l *stdLogger) Sync() error {
+	if l.file != nil {
+		return l.file.Sync()
+	}
+	return nil
+}
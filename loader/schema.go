@@ -0,0 +1,198 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File 是配置文件的结构
+//
+//	logging:
+//	  level: info
+//	  output: stderr
+//	defaults:
+//	  timeout: 5s
+//	  proxy: socks5://127.0.0.1:1080
+//	exchanges:
+//	  huobi:
+//	    credentials:
+//	      keystore: keys.json
+//	      passphrase_env: KEYSTORE_PASSPHRASE
+//	    rate_limit:
+//	      requests: 10
+//	      per: 1s
+//	  binance-sub:
+//	    type: binance
+//	    credentials:
+//	      env: BINANCE_SUB_
+//
+// defaults中的值作为每个交易所的默认值，交易所中设置的同名字段优先
+type File struct {
+	Logging   *Logging            `json:"logging"`
+	Defaults  Exchange            `json:"defaults"`
+	Exchanges map[string]Exchange `json:"exchanges"`
+}
+
+// Logging 日志配置
+type Logging struct {
+	Level  string `json:"level"`  // debug、info、warn或error，默认info
+	Output string `json:"output"` // stderr、stdout或文件路径，默认stderr
+}
+
+// Exchange 单个交易所的配置
+type Exchange struct {
+	Type         string       `json:"type"`        // 交易所类型，默认为配置中的名称
+	Environment  string       `json:"environment"` // production或testnet，默认production
+	RESTHost     string       `json:"rest_host"`
+	WSSHost      string       `json:"wss_host"`
+	RESTHosts    []string     `json:"rest_hosts"`   // 可以互相替代的一组rest地址，与rest_host只能设置一个
+	WSSHosts     []string     `json:"wss_hosts"`    // 可以互相替代的一组websocket地址，与wss_host只能设置一个
+	HealthCheck  Duration     `json:"health_check"` // 备用地址的主动健康检查间隔，见config.WithHealthCheck
+	UseSSL       *bool        `json:"use_ssl"`
+	DryRun       *bool        `json:"dry_run"` // 模拟交易，见config.WithDryRun
+	Account      string       `json:"account"` // 交易账户，见config.WithAccount
+	Proxy        string       `json:"proxy"`
+	Timeout      Duration     `json:"timeout"`
+	PingInterval Duration     `json:"ping_interval"`
+	TimeSync     Duration     `json:"time_sync"`
+	NonceFile    string       `json:"nonce_file"`
+	Retry        *Retry       `json:"retry"`
+	RateLimit    *RateLimit   `json:"rate_limit"`
+	Credentials  *Credentials `json:"credentials"`
+	Signer       *Signer      `json:"signer"`
+}
+
+// Retry 重试策略，对应config.RetryPolicy
+type Retry struct {
+	MaxRetries int      `json:"max_retries"`
+	MinBackoff Duration `json:"min_backoff"`
+	MaxBackoff Duration `json:"max_backoff"`
+}
+
+// RateLimit 客户端限流，每per时间内最多requests个请求
+type RateLimit struct {
+	Requests int      `json:"requests"`
+	Per      Duration `json:"per"` // 默认1s
+	Burst    int      `json:"burst"`
+}
+
+// Credentials 凭证的来源，只能设置apikey、env、file和keystore中的一种
+type Credentials struct {
+	APIKey        string `json:"apikey"`
+	Secret        string `json:"secret"`
+	Passphrase    string `json:"passphrase"`     // okex等交易所需要的API key密码，只能与apikey一起使用
+	Env           string `json:"env"`            // 环境变量前缀，见credential.Env
+	File          string `json:"file"`           // json或yaml凭证文件，见credential.File
+	Keystore      string `json:"keystore"`       // 加密的keystore文件，见credential.Keystore
+	PassphraseEnv string `json:"passphrase_env"` // 保存keystore口令的环境变量
+}
+
+// Signer 远程签名配置，见signer.Remote
+type Signer struct {
+	Remote  string   `json:"remote"` // 签名进程的unix socket
+	KeyID   string   `json:"key_id"` // 默认为配置中的名称
+	Timeout Duration `json:"timeout"`
+}
+
+// Duration 以"5s"、"300ms"形式书写的时间间隔，也可以是表示秒数的数字
+// 解析时只保存原始内容，在检查配置时才转换，以便错误信息带有字段路径
+type Duration string
+
+// UnmarshalJSON 保存时间间隔的原始内容
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b)
+		if _, nerr := strconv.ParseFloat(s, 64); nerr == nil {
+			s += "s"
+		}
+	}
+	*d = Duration(s)
+	return nil
+}
+
+// parse 转换为time.Duration
+func (d Duration) parse() (time.Duration, error) {
+	s := string(d)
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		// yaml中没有单位的数字按字符串保存，同样表示秒数
+		s += "s"
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("无效的时间间隔%s", string(d))
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("不能为负数")
+	}
+	return v, nil
+}
+
+// withDefaults 用d填充e中未设置的字段
+func (e Exchange) withDefaults(d Exchange) Exchange {
+	ev, dv := reflect.ValueOf(&e).Elem(), reflect.ValueOf(d)
+	for i := 0; i < ev.NumField(); i++ {
+		f := ev.Field(i)
+		if reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+			f.Set(dv.Field(i))
+		}
+	}
+	return e
+}
+
+// problems 收集配置中的错误
+type problems []string
+
+func (p *problems) add(path, format string, v ...interface{}) {
+	*p = append(*p, path+": "+fmt.Sprintf(format, v...))
+}
+
+// checkFields 检查raw中是否有t中不存在的字段，避免拼写错误的字段被静默忽略
+func checkFields(raw interface{}, t reflect.Type, path string, p *problems) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			fields[name] = t.Field(i).Type
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ft, ok := fields[k]
+			if !ok {
+				p.add(join(path, k), "未知的字段")
+				continue
+			}
+			checkFields(m[k], ft, join(path, k), p)
+		}
+	case reflect.Map:
+		if m, ok := raw.(map[string]interface{}); ok {
+			for k, v := range m {
+				checkFields(v, t.Elem(), join(path, k), p)
+			}
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,257 @@
+package metrics
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// Expvar 是基于expvar的config.Metrics实现
+// 所有指标以json形式发布在expvar的name变量下，Handler以文本格式输出同样的数据
+type Expvar struct {
+	mutex      sync.Mutex
+	requests   map[requestKey]*summary
+	waits      map[string]*summary
+	reconnects map[streamKey]int64
+	messages   map[streamKey]*rate
+	drops      map[streamKey]int64
+}
+
+var _ config.Metrics = (*Expvar)(nil)
+
+type requestKey struct {
+	exchange string
+	endpoint string
+	status   int
+	class    string
+}
+
+type streamKey struct {
+	exchange string
+	name     string
+}
+
+type summary struct {
+	count int64
+	sum   time.Duration
+}
+
+// rate 统计总数以及最近一个完整秒内的数量
+type rate struct {
+	total int64
+	sec   int64
+	cur   int64
+	last  int64
+}
+
+func (r *rate) inc(now int64) {
+	if now != r.sec {
+		if now == r.sec+1 {
+			r.last = r.cur
+		} else {
+			r.last = 0
+		}
+		r.sec, r.cur = now, 0
+	}
+	r.cur++
+	r.total++
+}
+
+func (r *rate) perSecond(now int64) int64 {
+	switch now {
+	case r.sec:
+		return r.last
+	case r.sec + 1:
+		return r.cur
+	}
+	return 0
+}
+
+// NewExpvar 创建一个Expvar并以name发布到expvar，name已被占用时不重复发布
+func NewExpvar(name string) *Expvar {
+	m := &Expvar{
+		requests:   make(map[requestKey]*summary),
+		waits:      make(map[string]*summary),
+		reconnects: make(map[streamKey]int64),
+		messages:   make(map[streamKey]*rate),
+		drops:      make(map[streamKey]int64),
+	}
+	if name != "" && expvar.Get(name) == nil {
+		expvar.Publish(name, expvar.Func(m.snapshot))
+	}
+	return m
+}
+
+// ObserveRequest 记录一次rest请求
+func (m *Expvar) ObserveRequest(exchange, endpoint string, status int, class string, dur time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	k := requestKey{exchange, endpoint, status, class}
+	s, ok := m.requests[k]
+	if !ok {
+		s = &summary{}
+		m.requests[k] = s
+	}
+	s.count++
+	s.sum += dur
+}
+
+// ObserveRateLimitWait 记录一次限流等待
+func (m *Expvar) ObserveRateLimitWait(exchange string, wait time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s, ok := m.waits[exchange]
+	if !ok {
+		s = &summary{}
+		m.waits[exchange] = s
+	}
+	s.count++
+	s.sum += wait
+}
+
+// IncReconnect 记录一次websocket重连
+func (m *Expvar) IncReconnect(exchange, stream string) {
+	m.mutex.Lock()
+	m.reconnects[streamKey{exchange, stream}]++
+	m.mutex.Unlock()
+}
+
+// IncMessage 记录订阅收到的一条消息
+func (m *Expvar) IncMessage(exchange, subscription string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	k := streamKey{exchange, subscription}
+	r, ok := m.messages[k]
+	if !ok {
+		r = &rate{}
+		m.messages[k] = r
+	}
+	r.inc(time.Now().Unix())
+}
+
+// IncDrop 记录一条被丢弃的订阅消息
+func (m *Expvar) IncDrop(exchange, subscription string) {
+	m.mutex.Lock()
+	m.drops[streamKey{exchange, subscription}]++
+	m.mutex.Unlock()
+}
+
+// Handler 返回以文本格式输出所有指标的http.Handler
+func (m *Expvar) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteText(w)
+	})
+}
+
+// WriteText 以文本格式输出所有指标，每行一个样本，同一指标内按标签排序
+func (m *Expvar) WriteText(w io.Writer) error {
+	var lines []string
+	add := func(name, labels string, v string) {
+		lines = append(lines, name+"{"+labels+"} "+v)
+	}
+	now := time.Now().Unix()
+
+	m.mutex.Lock()
+	for k, s := range m.requests {
+		l := labelPairs("exchange", k.exchange, "endpoint", k.endpoint,
+			"status", strconv.Itoa(k.status), "class", k.class)
+		add("exchange_sdk_request_duration_seconds_count", l, strconv.FormatInt(s.count, 10))
+		add("exchange_sdk_request_duration_seconds_sum", l, seconds(s.sum))
+	}
+	for ex, s := range m.waits {
+		l := labelPairs("exchange", ex)
+		add("exchange_sdk_rate_limit_wait_seconds_count", l, strconv.FormatInt(s.count, 10))
+		add("exchange_sdk_rate_limit_wait_seconds_sum", l, seconds(s.sum))
+	}
+	for k, n := range m.reconnects {
+		add("exchange_sdk_ws_reconnects_total", labelPairs("exchange", k.exchange, "stream", k.name),
+			strconv.FormatInt(n, 10))
+	}
+	for k, r := range m.messages {
+		l := labelPairs("exchange", k.exchange, "subscription", k.name)
+		add("exchange_sdk_ws_messages_total", l, strconv.FormatInt(r.total, 10))
+		add("exchange_sdk_ws_messages_per_second", l, strconv.FormatInt(r.perSecond(now), 10))
+	}
+	for k, n := range m.drops {
+		add("exchange_sdk_channel_drops_total", labelPairs("exchange", k.exchange, "subscription", k.name),
+			strconv.FormatInt(n, 10))
+	}
+	m.mutex.Unlock()
+
+	sort.Strings(lines)
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// snapshot 返回供expvar输出的数据
+func (m *Expvar) snapshot() interface{} {
+	type sample struct {
+		Labels map[string]string `json:"labels"`
+		Count  int64             `json:"count"`
+		Sum    float64           `json:"sum_seconds,omitempty"`
+		Rate   int64             `json:"per_second,omitempty"`
+	}
+	now := time.Now().Unix()
+	out := map[string][]sample{}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for k, s := range m.requests {
+		out["requests"] = append(out["requests"], sample{
+			Labels: map[string]string{"exchange": k.exchange, "endpoint": k.endpoint,
+				"status": strconv.Itoa(k.status), "class": k.class},
+			Count: s.count, Sum: s.sum.Seconds(),
+		})
+	}
+	for ex, s := range m.waits {
+		out["rate_limit_waits"] = append(out["rate_limit_waits"], sample{
+			Labels: map[string]string{"exchange": ex}, Count: s.count, Sum: s.sum.Seconds(),
+		})
+	}
+	for k, n := range m.reconnects {
+		out["reconnects"] = append(out["reconnects"], sample{
+			Labels: map[string]string{"exchange": k.exchange, "stream": k.name}, Count: n,
+		})
+	}
+	for k, r := range m.messages {
+		out["messages"] = append(out["messages"], sample{
+			Labels: map[string]string{"exchange": k.exchange, "subscription": k.name},
+			Count:  r.total, Rate: r.perSecond(now),
+		})
+	}
+	for k, n := range m.drops {
+		out["drops"] = append(out["drops"], sample{
+			Labels: map[string]string{"exchange": k.exchange, "subscription": k.name}, Count: n,
+		})
+	}
+	return out
+}
+
+func labelPairs(kv ...string) string {
+	var buf bytes.Buffer
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "%s=%q", kv[i], kv[i+1])
+	}
+	return buf.String()
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
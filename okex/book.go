@@ -0,0 +1,86 @@
+package okex
+
+import (
+	"hash/crc32"
+	"sort"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+// bookLevel 一档深度，保留原始字符串用于计算checksum
+type bookLevel struct {
+	px, sz string
+	price  float64
+}
+
+// orderBook 根据books频道的快照和增量在本地合并的深度，卖方价格升序、买方价格降序
+type orderBook struct {
+	asks, bids []bookLevel
+}
+
+// apply 合并一次推送，数量为0的档位被删除
+func (b *orderBook) apply(data *BookData) {
+	b.asks = mergeLevels(b.asks, data.Asks, false)
+	b.bids = mergeLevels(b.bids, data.Bids, true)
+}
+
+func mergeLevels(side []bookLevel, updates [][]string, desc bool) []bookLevel {
+	for _, u := range updates {
+		if len(u) < 2 {
+			continue
+		}
+		l := bookLevel{px: u[0], sz: u[1], price: toFloat(u[0])}
+		i := sort.Search(len(side), func(i int) bool {
+			if desc {
+				return side[i].price <= l.price
+			}
+			return side[i].price >= l.price
+		})
+		found := i < len(side) && side[i].price == l.price
+		switch {
+		case toFloat(l.sz) == 0:
+			if found {
+				side = append(side[:i], side[i+1:]...)
+			}
+		case found:
+			side[i] = l
+		default:
+			side = append(side, bookLevel{})
+			copy(side[i+1:], side[i:])
+			side[i] = l
+		}
+	}
+	return side
+}
+
+// checksum 按okex的规则计算前25档的crc32:
+// bid1价格:bid1数量:ask1价格:ask1数量:bid2价格:...，某一方档位不足时跳过
+func (b *orderBook) checksum() int32 {
+	var parts []string
+	for i := 0; i < 25; i++ {
+		if i < len(b.bids) {
+			parts = append(parts, b.bids[i].px, b.bids[i].sz)
+		}
+		if i < len(b.asks) {
+			parts = append(parts, b.asks[i].px, b.asks[i].sz)
+		}
+	}
+	return int32(crc32.ChecksumIEEE([]byte(strings.Join(parts, ":"))))
+}
+
+func (b *orderBook) depth(key global.TradeSymbol) global.Depth {
+	d := global.Depth{
+		Base:  key.Base,
+		Quote: key.Quote,
+		Asks:  make([]global.DepthPair, 0, len(b.asks)),
+		Bids:  make([]global.DepthPair, 0, len(b.bids)),
+	}
+	for _, l := range b.asks {
+		d.Asks = append(d.Asks, global.DepthPair{Price: l.price, Size: toFloat(l.sz)})
+	}
+	for _, l := range b.bids {
+		d.Bids = append(d.Bids, global.DepthPair{Price: l.price, Size: toFloat(l.sz)})
+	}
+	return d
+}
@@ -2,7 +2,6 @@ package okex
 
 import (
 	"bytes"
-	"compress/flate"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,311 +13,535 @@ import (
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
-	"github.com/google/uuid"
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
 	"github.com/gorilla/websocket"
 	"github.com/gotoxu/log/core"
-	jsoniter "github.com/json-iterator/go"
-	"github.com/json-iterator/go/extra"
 )
 
-// WSSClient 提供okex API调用的客户端
+// WSSClient 通过类型化的订阅接收okex公共频道的推送
+// k线频道与其他频道位于不同的地址，WSSClient按需为每个地址建立连接，断线后自动重连并重新订阅
 type WSSClient struct {
 	config config.Config
-	conns  map[string]*websocket.Conn
 	logger core.Logger
 
+	mutex   sync.Mutex
+	started bool
 	closed  bool
-	closeMu sync.Mutex
-	once    sync.Once
+	conns   map[string]*websocket.Conn // key为地址路径
+	dialing map[string]bool            // 正在建立连接的地址路径
+	subs    map[string][]Subscription  // 按地址路径分组的订阅
+	books   map[Subscription]*orderBook
 
+	out        chan Message
 	shouldQuit chan struct{}
-	retry      chan string
-	done       chan struct{}
+}
 
-	events []Event
+// Message WSSClient收到的消息
+// Event不为空时为订阅确认(subscribe、unsubscribe)或错误(error，此时Err不为nil)，
+// 否则为Sub频道的推送，按频道填充对应的字段
+type Message struct {
+	Event  string
+	Sub    Subscription
+	Err    error
+	Ticker *global.Ticker
+	Depth  *global.Depth // books频道为合并后的完整深度
+	Trades []global.LateTrade
+	Klines []global.Kline
 }
 
 // NewWSSClient 创建一个新的Websocket client
 func NewWSSClient(config *config.Config) *WSSClient {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(testnetConfig(), config)
 	}
 
 	return &WSSClient{
 		config:     *cfg,
+		logger:     cfg.ExchangeLogger("okex"),
 		conns:      make(map[string]*websocket.Conn),
+		dialing:    make(map[string]bool),
+		subs:       make(map[string][]Subscription),
+		books:      make(map[Subscription]*orderBook),
+		out:        make(chan Message, 100),
 		shouldQuit: make(chan struct{}),
-		retry:      make(chan string),
-		done:       make(chan struct{}),
 	}
 }
 
 // SetLogger 设置日志器
 func (c *WSSClient) SetLogger(logger core.Logger) {
-	c.logger = logger
-}
-
-// Subscribe 订阅
-func (c *WSSClient) Subscribe(events []Event) {
-	c.events = events
+	c.logger = config.RedactLogger(logger)
 }
 
-// QuerySpot 负责订阅现货行情数据
-func (c *WSSClient) QuerySpot() (<-chan []byte, error) {
-	cid, conn, err := c.connect("/websocket")
-	if err != nil {
-		return nil, err
+// errClosing WSSClient已经Close
+var errClosing = errors.New("Connection is closing")
+
+// Subscribe 添加订阅，Query之后调用时立即发送订阅请求
+func (c *WSSClient) Subscribe(subs ...Subscription) error {
+	c.mutex.Lock()
+	var added []Subscription
+	for _, s := range subs {
+		if c.indexOf(s) < 0 {
+			c.subs[s.path()] = append(c.subs[s.path()], s)
+			added = append(added, s)
+		}
 	}
-
-	err = c.subscribeSpot(conn)
+	if !c.started {
+		c.mutex.Unlock()
+		return nil
+	}
+	missing, err := c.send("subscribe", added)
+	c.mutex.Unlock()
 	if err != nil {
-		c.Close()
-		return nil, err
+		return err
 	}
+	// 新建的连接会订阅该地址上的所有频道
+	for _, path := range missing {
+		if err := c.connect(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	result := make(chan []byte)
-	go c.start(cid, "/websocket", result)
-
-	return result, nil
+// Unsubscribe 取消订阅
+func (c *WSSClient) Unsubscribe(subs ...Subscription) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var removed []Subscription
+	for _, s := range subs {
+		if i := c.indexOf(s); i >= 0 {
+			p := s.path()
+			c.subs[p] = append(c.subs[p][:i], c.subs[p][i+1:]...)
+			delete(c.books, s)
+			removed = append(removed, s)
+		}
+	}
+	if !c.started {
+		return nil
+	}
+	// 没有连接的地址重连时只会订阅剩下的频道
+	_, err := c.send("unsubscribe", removed)
+	return err
 }
 
-func (c *WSSClient) log(level core.Level, v ...interface{}) {
-	if c.logger != nil {
-		c.logger.Log(level, v...)
+func (c *WSSClient) indexOf(s Subscription) int {
+	for i, v := range c.subs[s.path()] {
+		if v == s {
+			return i
+		}
 	}
+	return -1
 }
 
-func (c *WSSClient) logf(level core.Level, format string, v ...interface{}) {
-	if c.logger != nil {
-		c.logger.Logf(level, format, v...)
+// send 按地址分组发送请求，返回没有连接的地址，调用方需持有c.mutex
+func (c *WSSClient) send(op string, subs []Subscription) ([]string, error) {
+	groups := make(map[string][]Subscription)
+	for _, s := range subs {
+		groups[s.path()] = append(groups[s.path()], s)
+	}
+	var missing []string
+	for path, args := range groups {
+		conn, ok := c.conns[path]
+		if !ok {
+			missing = append(missing, path)
+			continue
+		}
+		if err := c.config.WriteJSONFrame("okex", conn, subRequest(op, args...)); err != nil {
+			return missing, err
+		}
 	}
+	return missing, nil
 }
 
-func (c *WSSClient) logln(level core.Level, v ...interface{}) {
-	if c.logger != nil {
-		c.logger.Logln(level, v...)
+// Query 连接并订阅所有已添加的频道，返回接收消息的channel
+// channel缓冲100条，读取不及时时新消息会被丢弃
+func (c *WSSClient) Query() (<-chan Message, error) {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil, errClosing
+	}
+	var paths []string
+	if !c.started {
+		c.started = true
+		for path := range c.subs {
+			paths = append(paths, path)
+		}
 	}
+	c.mutex.Unlock()
+	for _, path := range paths {
+		if err := c.connect(path); err != nil {
+			return nil, err
+		}
+	}
+	return c.out, nil
 }
 
-func (c *WSSClient) subscribeSpot(conn *websocket.Conn) error {
-	for _, v := range c.events {
-		err := conn.WriteJSON(v)
-		if err != nil {
+// connect 连接path并订阅其上的所有频道，已经连接或正在连接时直接返回
+// 建立连接时不持有c.mutex，连接期间添加的频道在连接成功后一起订阅
+func (c *WSSClient) connect(path string) error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return errClosing
+	}
+	if _, ok := c.conns[path]; ok || c.dialing[path] {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.dialing[path] = true
+	c.mutex.Unlock()
+
+	conn, _, err := c.config.DialWSS(wsURL(&c.config, path), nil)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.dialing, path)
+	if err != nil {
+		return err
+	}
+	if c.closed {
+		conn.Close()
+		return errClosing
+	}
+	if subs := c.subs[path]; len(subs) != 0 {
+		if err := c.config.WriteJSONFrame("okex", conn, subRequest("subscribe", subs...)); err != nil {
+			conn.Close()
 			return err
 		}
 	}
-
+	c.conns[path] = conn
+	done := make(chan struct{})
+	go c.ping(conn, done)
+	go c.read(path, conn, done)
 	return nil
 }
 
-func (c *WSSClient) start(cid, path string, msgCh chan<- []byte) {
-	go c.query(cid, msgCh)
-
+func (c *WSSClient) ping(conn *websocket.Conn, done chan struct{}) {
 	ticker := time.NewTicker(*c.config.PingDuration)
 	defer ticker.Stop()
-
 	for {
 		select {
 		case <-ticker.C:
-			conn := c.conns[cid]
-			conn.WriteMessage(websocket.TextMessage, []byte("{'event':'ping'}"))
-		case cid := <-c.retry:
-			delete(c.conns, cid)
-			c.reconnect(path, msgCh)
-		case <-c.shouldQuit:
-			c.shutdown()
+			c.mutex.Lock()
+			c.config.WriteFrame("okex", conn, websocket.TextMessage, []byte("ping"))
+			c.mutex.Unlock()
+		case <-done:
 			return
 		}
 	}
 }
 
-func (c *WSSClient) shutdown() {
-	c.closeMu.Lock()
-	c.closed = true
-	c.closeMu.Unlock()
-
-	for _, conn := range c.conns {
-		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		conn.Close()
+func (c *WSSClient) read(path string, conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
+	for {
+		_, msg, err := c.config.ReadFrame("okex", conn)
+		if err != nil {
+			c.reconnect(path, conn)
+			return
+		}
+		if string(msg) == "pong" {
+			continue
+		}
+		c.handle(conn, msg)
 	}
-
-	close(c.done)
 }
 
-func (c *WSSClient) reconnect(path string, msgCh chan<- []byte) {
-	cid, conn, err := c.connect(path)
-	if err != nil {
+// reconnect 每秒重试一次，直到连接成功或Close
+func (c *WSSClient) reconnect(path string, old *websocket.Conn) {
+	c.mutex.Lock()
+	if c.closed || c.conns[path] != old {
+		c.mutex.Unlock()
 		return
 	}
+	delete(c.conns, path)
+	for _, s := range c.subs[path] {
+		delete(c.books, s)
+	}
+	c.mutex.Unlock()
+	c.config.ObserveReconnect("okex", path)
 
-	err = c.subscribeSpot(conn)
-	if err != nil {
-		c.closeMu.Lock()
-		defer c.closeMu.Unlock()
-
-		if !c.closed {
-			c.retry <- cid
+	for {
+		select {
+		case <-c.shouldQuit:
+			return
+		case <-time.After(time.Second):
 		}
-		return
+		err := c.connect(path)
+		if err == nil || err == errClosing {
+			return
+		}
+		c.logf(core.Warn, "okex 重连 %s 失败: %s", path, err.Error())
 	}
-
-	go c.query(cid, msgCh)
 }
 
-func (c *WSSClient) query(cid string, msgCh chan<- []byte) {
-	conn, ok := c.conns[cid]
-	if !ok {
+func (c *WSSClient) handle(conn *websocket.Conn, msg []byte) {
+	var m wsMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		c.logf(core.Warn, "json unmarshal %s", err.Error())
+		return
+	}
+	if m.Event != "" {
+		r := Message{Event: m.Event, Sub: m.Arg}
+		if m.Event == "error" {
+			r.Err = &Error{Code: m.Code, Message: m.Msg}
+		}
+		c.emit(r, "event:"+m.Event)
 		return
 	}
 
-	for {
-		_, msg, err := conn.ReadMessage()
-		if err != nil {
-			c.closeMu.Lock()
-			defer c.closeMu.Unlock()
-
-			if !c.closed {
-				c.retry <- cid
-			}
+	key := splitInstID(m.Arg.InstID)
+	r := Message{Sub: m.Arg}
+	switch ch := m.Arg.Channel; {
+	case ch == ChannelTickers:
+		var data []Ticker
+		if err := json.Unmarshal(m.Data, &data); err != nil || len(data) == 0 {
 			return
 		}
-
-		if strings.Contains(string(msg), "pong") {
-			continue
+		t := data[0].toTicker(key)
+		r.Ticker = &t
+	case ch == ChannelBooks5:
+		var data []BookData
+		if err := json.Unmarshal(m.Data, &data); err != nil || len(data) == 0 {
+			return
 		}
-
-		buf := bytes.NewBuffer(msg)
-		z := flate.NewReader(buf)
-		m, _ := ioutil.ReadAll(z)
-
-		// 作为sdk开发者来说，我们并不知晓调用方需要哪些数据，因此这里不做过滤，而在调用方再做过滤
-		// 从代码致性效率来说，这里反序列化一次，然后将消息传给调用方，调用方仍然需要反序列化，因此直接在调用方做反序列化并过滤
-		// 而sdk中不做处理
-		// var subrsp [1]struct {
-		// 	Data struct {
-		// 		Result string `json:"result"`
-		// 	} `json:"data"`
-		// }
-		// if e := json.Unmarshal(m, &subrsp); e != nil {
-		// 	// 订阅请求的回复，不包含数据，直接忽略
-		// 	log.Print("ignore subscribe respone.")
-		// 	continue
-		// }
-		msgCh <- m
+		d := data[0].toDepth(key)
+		r.Depth = &d
+	case ch == ChannelBooks:
+		var data []BookData
+		if err := json.Unmarshal(m.Data, &data); err != nil || len(data) == 0 {
+			return
+		}
+		d, ok := c.mergeBook(conn, m.Arg, m.Action, &data[0])
+		if !ok {
+			return
+		}
+		r.Depth = &d
+	case ch == ChannelTrades:
+		var data []Trade
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			return
+		}
+		for i := range data {
+			r.Trades = append(r.Trades, data[i].toLateTrade(key))
+		}
+	case strings.HasPrefix(ch, ChannelCandle):
+		var data [][]string
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			return
+		}
+		r.Klines = toKlines(key, data)
+	default:
+		return
 	}
+	subscription := m.Arg.Channel + ":" + key.Base + key.Quote
+	c.config.ObserveMessage("okex", subscription)
+	c.emit(r, subscription)
 }
 
-// Close 向服务端发起关闭操作
-func (c *WSSClient) Close() {
-	if c.conns == nil {
-		return
+// mergeBook 合并books频道的推送，checksum不一致时丢弃本地深度并重新订阅
+func (c *WSSClient) mergeBook(conn *websocket.Conn, sub Subscription, action string, data *BookData) (global.Depth, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	b, ok := c.books[sub]
+	if action == "snapshot" {
+		b, ok = &orderBook{}, true
+		c.books[sub] = b
 	}
+	if !ok {
+		// 等待重新订阅后的快照
+		return global.Depth{}, false
+	}
+	b.apply(data)
+	if sum := b.checksum(); sum != data.Checksum {
+		c.logf(core.Warn, "okex %s checksum不一致(本地%d, 推送%d)，重新订阅", sub.InstID, sum, data.Checksum)
+		delete(c.books, sub)
+		c.config.WriteJSONFrame("okex", conn, subRequest("unsubscribe", sub))
+		c.config.WriteJSONFrame("okex", conn, subRequest("subscribe", sub))
+		return global.Depth{}, false
+	}
+	return b.depth(splitInstID(sub.InstID)), true
+}
 
-	close(c.shouldQuit)
-
+// emit 推送消息，channel已满时丢弃并上报Metrics.IncDrop，不阻塞读取
+func (c *WSSClient) emit(m Message, subscription string) {
 	select {
-	case <-c.done:
-	case <-time.After(time.Second):
+	case c.out <- m:
+	case <-c.shouldQuit:
+	default:
+		c.config.ObserveDrop("okex", subscription)
 	}
 }
 
-func (c *WSSClient) connect(path string) (string, *websocket.Conn, error) {
-	u := url.URL{Scheme: "wss", Host: *c.config.WSSHost, Path: path}
-	conn, _, err := c.config.WSSDialer.Dial(u.String(), nil)
-	if err == nil {
-		u := uuid.New().String()
-		c.conns[u] = conn
-		return u, conn, nil
+func (c *WSSClient) logf(level core.Level, format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Logf(level, format, v...)
 	}
+}
 
-	if err == websocket.ErrBadHandshake {
-		return "", nil, err
+// Close 关闭所有连接
+func (c *WSSClient) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return
 	}
-
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			conn, _, err := c.config.WSSDialer.Dial(u.String(), nil)
-			if err == nil {
-				u := uuid.New().String()
-				c.conns[u] = conn
-				return u, conn, nil
-			}
-		case <-c.shouldQuit:
-			return "", nil, errors.New("Connection is closing")
-		}
+	c.closed = true
+	close(c.shouldQuit)
+	for path, conn := range c.conns {
+		c.config.WriteFrame("okex", conn, websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		conn.Close()
+		delete(c.conns, path)
 	}
 }
 
-// Client ...
+// Client 提供okex v5 API的调用客户端
 type Client struct {
-	config config.Config
+	config    config.Config
+	clock     *config.Clock
+	logger    core.Logger
+	sock      *websocket.Conn
+	once      sync.Once
+	mutex     sync.Mutex
+	replay    bool
+	tick      map[global.TradeSymbol]chan global.Ticker
+	depth     map[global.TradeSymbol]chan global.Depth
+	latetrade map[global.TradeSymbol]chan global.LateTrade
 }
 
 // NewClient 创建一个新的client
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(testnetConfig(), config)
+	}
+	cfg.ApplyDryRun("okex", dryRun)
+
+	c := &Client{
+		config:    *cfg,
+		logger:    cfg.ExchangeLogger("okex"),
+		tick:      make(map[global.TradeSymbol]chan global.Ticker),
+		depth:     make(map[global.TradeSymbol]chan global.Depth),
+		latetrade: make(map[global.TradeSymbol]chan global.LateTrade),
 	}
+	c.clock = cfg.StartClock(c.serverTime)
+	return c
+}
 
-	return &Client{config: *cfg}
+// SetLogger 设置日志器
+func (c *Client) SetLogger(logger core.Logger) {
+	c.logger = config.RedactLogger(logger)
 }
 
-func (c *Client) doHTTP(method, path string,
-	reqarg interface{}, out interface{}, extraheader map[string]string) error {
-	url := "http://"
-	if *c.config.UseSSL {
-		url = "https://"
-	}
-	url += *c.config.RESTHost + path
+// Error okex接口返回的业务错误
+type Error struct {
+	Code    string
+	Message string
+}
 
-	arg := ""
-	if method == "POST" {
-		bytesParams, _ := json.Marshal(reqarg)
-		arg = string(bytesParams)
-	}
-	req, e := http.NewRequest(method, url, strings.NewReader(arg))
-	if e != nil {
-		return e
-	}
-	if method == "GET" {
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	} else {
-		req.Header.Set("Content-Type", "application/json")
+// Error ...
+func (e *Error) Error() string {
+	return fmt.Sprintf("okex error %s: %s", e.Code, e.Message)
+}
+
+// doHTTP 发送rest请求并将data字段解析到out，sign为true时使用API key签名
+// params编码到query中，body不为nil时编码为json请求体
+func (c *Client) doHTTP(method, path string, params map[string]string,
+	body interface{}, out interface{}, sign bool) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.config.ObserveRequest("okex", endpoint, status, err, start) }()
+	if len(params) != 0 {
+		q := url.Values{}
+		for k, v := range params {
+			q.Set(k, v)
+		}
+		path += "?" + q.Encode()
 	}
-	req.Header.Set("Accept-Language", "zh-cn")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) "+
-		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
-	for k, v := range extraheader {
-		req.Header.Set(k, v)
+	var payload []byte
+	if body != nil {
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
 	}
-	resp, re := c.config.HTTPClient.Do(req)
-	if e != nil {
-		return re
+	u := "http://"
+	if *c.config.UseSSL {
+		u = "https://"
 	}
+	u += c.config.RESTEndpoint() + path
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+	req, err := http.NewRequest(method, u, bytes.NewReader(payload))
+	if err != nil {
+		return err
 	}
-
-	body, err := ioutil.ReadAll(resp.Body)
+	req.Header.Set("Content-Type", "application/json")
+	if sign {
+		cred, s, err := c.config.Signing("okex", signer.HMACSHA256Base64)
+		if err != nil {
+			return err
+		}
+		// 签名内容为 timestamp + method + requestPath(含query) + body
+		ts := c.clock.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		sig, err := s.Sign([]byte(ts + method + path + string(payload)))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("OK-ACCESS-KEY", cred.APIKey)
+		req.Header.Set("OK-ACCESS-SIGN", sig)
+		req.Header.Set("OK-ACCESS-TIMESTAMP", ts)
+		req.Header.Set("OK-ACCESS-PASSPHRASE", cred.Passphrase)
+	}
+	ctx, cancel := c.config.RequestContext()
+	defer cancel()
+	resp, err := c.config.Do("okex", req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
+	defer resp.Body.Close()
 
-	fmt.Printf("okex http message:%s\n", string(body))
-	extra.RegisterFuzzyDecoders()
-
-	err = jsoniter.Unmarshal(body, out)
+	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	return nil
+	config.LogResponse(c.logger, req, start, b)
+
+	he := &global.HTTPError{StatusCode: resp.StatusCode}
+	if resp.StatusCode != http.StatusOK && he.Temporary() {
+		return he
+	}
+	r := struct {
+		Code string          `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	if e := json.Unmarshal(b, &r); e != nil || r.Code == "" {
+		if resp.StatusCode != http.StatusOK {
+			return he
+		}
+		if e != nil {
+			return e
+		}
+	}
+	if r.Code != "0" {
+		oe := &Error{Code: r.Code, Message: r.Msg}
+		// 下单、撤单失败时具体原因在data中
+		var items []OrderResult
+		if json.Unmarshal(r.Data, &items) == nil && len(items) != 0 &&
+			items[0].SCode != "" && items[0].SCode != "0" {
+			oe.Code, oe.Message = items[0].SCode, items[0].SMsg
+		}
+		return oe
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Data, out)
+}
+
+// doHTTPRetry 按配置的重试策略执行幂等的doHTTP请求
+func (c *Client) doHTTPRetry(method, path string, params map[string]string, out interface{}, sign bool) error {
+	return c.config.RetryDo("okex", func() error {
+		return c.doHTTP(method, path, params, nil, out, sign)
+	})
 }
@@ -0,0 +1,86 @@
+package okex
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/gorilla/websocket"
+)
+
+func TestWSSClientDialOutsideLock(t *testing.T) {
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+	subscribed := make(chan []Subscription, 10)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(dialing)
+		<-release
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				Op   string         `json:"op"`
+				Args []Subscription `json:"args"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			if req.Op == "subscribe" {
+				subscribed <- req.Args
+			}
+		}
+	}))
+	defer srv.Close()
+
+	cfg := (&config.Config{}).
+		WithWSSHost(strings.TrimPrefix(srv.URL, "https://")).
+		WithWSSDialer(&websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}})
+	c := NewWSSClient(cfg)
+	defer c.Close()
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	defer unblock()
+	if err := c.Subscribe(Tickers("BTC", "USDT")); err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Query()
+		errc <- err
+	}()
+	<-dialing
+
+	// 连接还在建立时，添加订阅不会被阻塞
+	done := make(chan error, 1)
+	go func() { done <- c.Subscribe(Tickers("ETH", "USDT")) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Subscribe blocked by a pending dial")
+	}
+
+	unblock()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case args := <-subscribed:
+		if len(args) != 2 {
+			t.Errorf("subscribed %v, want both tickers", args)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscribe request")
+	}
+}
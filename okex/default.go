@@ -11,17 +11,27 @@ import (
 
 func defaultConfig() *config.Config {
 	cfg := &config.Config{}
-
-	cfg.WithWSSHost("okexcomreal.bafang.com:10441")
-	cfg.WithHTTPClient(clean.DefaultPooledClient())
-	cfg.WithWSSDialer(websocket.DefaultDialer)
-	cfg.WithUseSSL(true)
-	cfg.WithPingDuration(60 * time.Second)
-
-	//
-	cfg.WithRESTHost("www.okex.cn")
+	cfg.WithWSSHost("ws.okx.com:8443")
+	cfg.WithRESTHost("www.okx.com")
 	cfg.WithUseSSL(true)
+	// 服务端30秒内没有收到消息会断开连接
+	cfg.WithPingDuration(20 * time.Second)
 	transport := clean.DefaultPooledTransport()
 	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithWSSDialer(websocket.DefaultDialer)
+	return cfg
+}
+
+// testnetConfig 模拟盘，rest请求需要附带x-simulated-trading头
+func testnetConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.WithWSSHost("wspap.okx.com:8443")
+	cfg.WithRESTHost("www.okx.com")
+	cfg.WithMiddleware(func(next config.RoundTripFunc) config.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("x-simulated-trading", "1")
+			return next(req)
+		}
+	})
 	return cfg
 }
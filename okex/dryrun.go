@@ -0,0 +1,44 @@
+package okex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单和撤单的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	if req.Method != "POST" {
+		return nil, false
+	}
+	arg := struct {
+		OrdID   string `json:"ordId"`
+		ClOrdID string `json:"clOrdId"`
+	}{}
+	switch req.URL.Path {
+	case "/api/v5/trade/order":
+		arg.OrdID = fmt.Sprintf("%d", config.DryRunOrderID())
+	case "/api/v5/trade/cancel-order":
+	default:
+		return nil, false
+	}
+	if req.Body != nil {
+		// 位于中间件的最内层，读取请求体不影响其他中间件
+		if b, err := ioutil.ReadAll(req.Body); err == nil {
+			id := arg.OrdID
+			json.Unmarshal(b, &arg)
+			if id != "" {
+				arg.OrdID = id
+			}
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"code": "0",
+		"msg":  "",
+		"data": []OrderResult{{OrdID: arg.OrdID, ClOrdID: arg.ClOrdID, SCode: "0"}},
+	})
+	return data, true
+}
@@ -0,0 +1,152 @@
+package okex
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
+)
+
+func (c *Client) parse(msg []byte) {
+	var m wsMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+		return
+	}
+	switch m.Event {
+	case "":
+	case "error":
+		c.logger.Logf(core.Warn, "okex 订阅失败 %s %s", m.Code, m.Msg)
+		return
+	default:
+		return
+	}
+
+	key := splitInstID(m.Arg.InstID)
+	switch m.Arg.Channel {
+	case "tickers":
+		c.mutex.Lock()
+		ch, ok := c.tick[key]
+		c.mutex.Unlock()
+		if !ok {
+			c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
+			return
+		}
+		var data []Ticker
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+			return
+		}
+		for _, t := range data {
+			c.config.ObserveMessage("okex", "ticker:"+key.Base+key.Quote)
+			select {
+			case ch <- t.toTicker(key):
+			default:
+				c.config.ObserveDrop("okex", "ticker:"+key.Base+key.Quote)
+			}
+		}
+	case "books5":
+		c.mutex.Lock()
+		ch, ok := c.depth[key]
+		c.mutex.Unlock()
+		if !ok {
+			c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
+			return
+		}
+		var data []BookData
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+			return
+		}
+		for _, b := range data {
+			c.config.ObserveMessage("okex", "depth:"+key.Base+key.Quote)
+			select {
+			case ch <- b.toDepth(key):
+			default:
+				c.config.ObserveDrop("okex", "depth:"+key.Base+key.Quote)
+			}
+		}
+	case "trades":
+		c.mutex.Lock()
+		ch, ok := c.latetrade[key]
+		c.mutex.Unlock()
+		if !ok {
+			c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
+			return
+		}
+		var data []Trade
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			c.logger.Logf(core.Warn, "json unmarshal %s", err.Error())
+			return
+		}
+		for _, d := range data {
+			c.config.ObserveMessage("okex", "trade:"+key.Base+key.Quote)
+			select {
+			case ch <- d.toLateTrade(key):
+			default:
+				c.config.ObserveDrop("okex", "trade:"+key.Base+key.Quote)
+			}
+		}
+	}
+}
+
+func (t *Ticker) toTicker(key global.TradeSymbol) global.Ticker {
+	open, last := toFloat(t.Open24h), toFloat(t.Last)
+	ret := global.Ticker{
+		Base:        key.Base,
+		Quote:       key.Quote,
+		PriceChange: last - open,
+		LastPrice:   last,
+		HighPrice:   toFloat(t.High24h),
+		LowPrice:    toFloat(t.Low24h),
+		Volume:      toFloat(t.Vol24h),
+	}
+	if open != 0 {
+		ret.PriceChangePercent = (last - open) / open * 100
+	}
+	return ret
+}
+
+func (b *BookData) toDepth(key global.TradeSymbol) global.Depth {
+	return global.Depth{
+		Base:  key.Base,
+		Quote: key.Quote,
+		Asks:  toDepthPairs(b.Asks),
+		Bids:  toDepthPairs(b.Bids),
+	}
+}
+
+func (d *Trade) toLateTrade(key global.TradeSymbol) global.LateTrade {
+	price, num := toFloat(d.Px), toFloat(d.Sz)
+	return global.LateTrade{
+		Base:      key.Base,
+		Quote:     key.Quote,
+		DateTime:  time.Unix(toInt(d.Ts)/1000, 0).Format("2006-01-02 03:04:05 PM"),
+		Num:       num,
+		Price:     price,
+		Dircetion: d.Side,
+		Total:     price * num,
+	}
+}
+
+// toKlines 转换candle频道推送的[ts, o, h, l, c, vol, ...]
+func toKlines(key global.TradeSymbol, rows [][]string) []global.Kline {
+	ret := make([]global.Kline, 0, len(rows))
+	for _, k := range rows {
+		if len(k) < 6 {
+			continue
+		}
+		ret = append(ret, global.Kline{
+			Base:      key.Base,
+			Quote:     key.Quote,
+			Timestamp: toInt(k[0]),
+			Open:      toFloat(k[1]),
+			High:      toFloat(k[2]),
+			Low:       toFloat(k[3]),
+			Close:     toFloat(k[4]),
+			Volume:    toFloat(k[5]),
+		})
+	}
+	return ret
+}
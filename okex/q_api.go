@@ -0,0 +1,83 @@
+package okex
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+func (c *Client) serverTime() (time.Time, error) {
+	var r []struct {
+		Ts string `json:"ts"`
+	}
+	if e := c.doHTTP("GET", "/api/v5/public/time", nil, nil, &r, false); e != nil {
+		return time.Time{}, e
+	}
+	if len(r) == 0 {
+		return time.Time{}, &Error{Message: "empty server time"}
+	}
+	return time.Unix(0, toInt(r[0].Ts)*int64(time.Millisecond)), nil
+}
+
+// GetAllSymbol 获取所有交易中的币币交易对
+func (c *Client) GetAllSymbol() ([]global.TradeSymbol, error) {
+	var r []Instrument
+	e := c.doHTTPRetry("GET", "/api/v5/public/instruments", map[string]string{"instType": "SPOT"}, &r, false)
+	if e != nil {
+		return nil, e
+	}
+	ret := make([]global.TradeSymbol, 0, len(r))
+	for _, i := range r {
+		if i.State != "live" {
+			continue
+		}
+		ret = append(ret, global.TradeSymbol{Base: i.BaseCcy, Quote: i.QuoteCcy})
+	}
+	return ret, nil
+}
+
+// GetKline 获取k线数据
+// Period 支持1m、3m、5m、15m、30m、1h、2h、4h、6h、12h、1d、1w，Count最大300
+func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
+	in := map[string]string{
+		"instId": instID(req.Base, req.Quote),
+		"bar":    klineBar(req.Period),
+	}
+	if req.Count > 0 {
+		in["limit"] = strconv.FormatInt(req.Count, 10)
+	}
+	// [ts, o, h, l, c, vol, ...]，按时间倒序
+	var r [][]string
+	if e := c.doHTTPRetry("GET", "/api/v5/market/candles", in, &r, false); e != nil {
+		return nil, e
+	}
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return toKlines(global.TradeSymbol{Base: req.Base, Quote: req.Quote}, r), nil
+}
+
+// klineBar 将1h、1d等周期转换为okex的1H、1D，分钟保持小写
+func klineBar(period string) string {
+	if strings.HasSuffix(period, "m") {
+		return period
+	}
+	return strings.ToUpper(period)
+}
+
+// GetDepth 获取深度，最多400档
+func (c *Client) GetDepth(sreq global.TradeSymbol) (global.Depth, error) {
+	in := map[string]string{"instId": instID(sreq.Base, sreq.Quote), "sz": "20"}
+	var r []BookData
+	if e := c.doHTTPRetry("GET", "/api/v5/market/books", in, &r, false); e != nil {
+		return global.Depth{}, e
+	}
+	ret := global.Depth{Base: sreq.Base, Quote: sreq.Quote}
+	if len(r) != 0 {
+		ret.Asks = toDepthPairs(r[0].Asks)
+		ret.Bids = toDepthPairs(r[0].Bids)
+	}
+	return ret, nil
+}
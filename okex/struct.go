@@ -0,0 +1,85 @@
+package okex
+
+import "encoding/json"
+
+// Instrument 交易产品
+type Instrument struct {
+	InstID   string `json:"instId"`   // 产品id，如BTC-USDT
+	BaseCcy  string `json:"baseCcy"`  // 交易货币
+	QuoteCcy string `json:"quoteCcy"` // 计价货币
+	State    string `json:"state"`    // live：交易中，suspend：暂停中，preopen：预上线
+}
+
+// BalanceDetail 交易账户中单个币种的余额
+type BalanceDetail struct {
+	Ccy       string `json:"ccy"`
+	AvailBal  string `json:"availBal"`  // 可用余额
+	FrozenBal string `json:"frozenBal"` // 冻结余额
+}
+
+// OrderResult 下单、撤单的结果
+type OrderResult struct {
+	OrdID   string `json:"ordId"`
+	ClOrdID string `json:"clOrdId"`
+	SCode   string `json:"sCode"` // 0表示成功
+	SMsg    string `json:"sMsg"`
+}
+
+// OrderDetail 订单信息
+type OrderDetail struct {
+	InstID    string `json:"instId"`
+	OrdID     string `json:"ordId"`
+	ClOrdID   string `json:"clOrdId"`
+	Px        string `json:"px"`        // 委托价格
+	Sz        string `json:"sz"`        // 委托数量
+	OrdType   string `json:"ordType"`   // market、limit、post_only、fok、ioc
+	Side      string `json:"side"`      // buy、sell
+	State     string `json:"state"`     // live、partially_filled、filled、canceled
+	AccFillSz string `json:"accFillSz"` // 累计成交数量
+	AvgPx     string `json:"avgPx"`     // 成交均价
+	FillPx    string `json:"fillPx"`    // 最新成交价格
+	FillSz    string `json:"fillSz"`    // 最新成交数量
+	Fee       string `json:"fee"`       // 手续费，为负数表示扣除
+	FeeCcy    string `json:"feeCcy"`
+	UTime     string `json:"uTime"`
+	CTime     string `json:"cTime"`
+}
+
+// wsMessage websocket的事件和推送
+type wsMessage struct {
+	Event  string          `json:"event"` // subscribe、unsubscribe、error，推送时为空
+	Code   string          `json:"code"`
+	Msg    string          `json:"msg"`
+	Arg    Subscription    `json:"arg"`
+	Action string          `json:"action"` // books频道: snapshot、update
+	Data   json.RawMessage `json:"data"`
+}
+
+// Ticker tickers频道推送
+type Ticker struct {
+	InstID  string `json:"instId"`
+	Last    string `json:"last"`
+	Open24h string `json:"open24h"`
+	High24h string `json:"high24h"`
+	Low24h  string `json:"low24h"`
+	Vol24h  string `json:"vol24h"` // 24小时成交量，以交易货币为单位
+	Ts      string `json:"ts"`
+}
+
+// BookData 深度数据，rest接口和books5、books频道通用，档位为[价格, 数量, 废弃字段, 订单数]
+type BookData struct {
+	Asks     [][]string `json:"asks"`
+	Bids     [][]string `json:"bids"`
+	Ts       string     `json:"ts"`
+	Checksum int32      `json:"checksum"`
+}
+
+// Trade trades频道推送
+type Trade struct {
+	InstID  string `json:"instId"`
+	TradeID string `json:"tradeId"`
+	Px      string `json:"px"`
+	Sz      string `json:"sz"`
+	Side    string `json:"side"`
+	Ts      string `json:"ts"`
+}
@@ -0,0 +1,146 @@
+package okex
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
+)
+
+// SubTicker 订阅tickers频道
+func (c *Client) SubTicker(sreq global.TradeSymbol) (chan global.Ticker, error) {
+	c.once.Do(func() { c.wsConnect() })
+	sreq = upperSymbol(sreq)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.subscribe(Tickers(sreq.Base, sreq.Quote)); err != nil {
+		return nil, err
+	}
+	ch := make(chan global.Ticker, 100)
+	c.tick[sreq] = ch
+	return ch, nil
+}
+
+// SubDepth 订阅books5频道，每次推送完整的5档深度
+func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
+	c.once.Do(func() { c.wsConnect() })
+	sreq = upperSymbol(sreq)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.subscribe(Books5(sreq.Base, sreq.Quote)); err != nil {
+		return nil, err
+	}
+	ch := make(chan global.Depth, 100)
+	c.depth[sreq] = ch
+	return ch, nil
+}
+
+// SubLateTrade 订阅trades频道
+func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, error) {
+	c.once.Do(func() { c.wsConnect() })
+	sreq = upperSymbol(sreq)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.subscribe(Trades(sreq.Base, sreq.Quote)); err != nil {
+		return nil, err
+	}
+	ch := make(chan global.LateTrade, 100)
+	c.latetrade[sreq] = ch
+	return ch, nil
+}
+
+func upperSymbol(sreq global.TradeSymbol) global.TradeSymbol {
+	return global.TradeSymbol{Base: strings.ToUpper(sreq.Base), Quote: strings.ToUpper(sreq.Quote)}
+}
+
+// subscribe 发送订阅请求，调用方需持有c.mutex
+func (c *Client) subscribe(sub Subscription) error {
+	if c.sock == nil {
+		return errors.New("connect failed")
+	}
+	return c.config.WriteJSONFrame("okex", c.sock, subRequest("subscribe", sub))
+}
+
+func (c *Client) wsConnect() error {
+	u := wsURL(&c.config, pathPublic)
+	c.logger.Logf(core.Info, "okex 连接 %s 中... ", u)
+	conn, _, err := c.config.DialWSS(u, nil)
+	c.logger.Logf(core.Info, "连接: %s", utils.Ternary(err == nil, "成功", "失败").(string))
+	c.mutex.Lock()
+	c.sock = conn
+	c.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	//在这儿进行订阅消息重放
+	if c.replay {
+		c.logger.Logf(core.Info, "连接成功，进行消息重放")
+		var args []Subscription
+		c.mutex.Lock()
+		for k := range c.tick {
+			args = append(args, Tickers(k.Base, k.Quote))
+		}
+		for k := range c.depth {
+			args = append(args, Books5(k.Base, k.Quote))
+		}
+		for k := range c.latetrade {
+			args = append(args, Trades(k.Base, k.Quote))
+		}
+		if len(args) != 0 {
+			if err := c.config.WriteJSONFrame("okex", conn, subRequest("subscribe", args...)); err != nil {
+				c.logger.Logf(core.Warn, "订阅消息重放失败 %s", err.Error())
+			}
+		}
+		c.mutex.Unlock()
+	}
+	c.replay = true
+
+	done := make(chan struct{})
+	go c.keepalive(conn, done)
+	// 循环读取消息
+	go func() {
+		defer close(done)
+		for {
+			_, msg, err := c.config.ReadFrame("okex", conn)
+			if err != nil {
+				c.logger.Logf(core.Warn, "okex < %s > 断开连接，五秒后重连...", err.Error())
+				c.config.ObserveReconnect("okex", "public")
+				go func() {
+					for {
+						time.Sleep(5 * time.Second)
+						if c.wsConnect() == nil {
+							return
+						}
+					}
+				}()
+				return
+			}
+			if string(msg) == "pong" {
+				continue
+			}
+			c.parse(msg)
+		}
+	}()
+	return nil
+}
+
+// keepalive 定时发送ping，服务端30秒内没有收到消息会断开连接
+func (c *Client) keepalive(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(*c.config.PingDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.mutex.Lock()
+			c.config.WriteFrame("okex", conn, websocket.TextMessage, []byte("ping"))
+			c.mutex.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
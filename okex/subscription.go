@@ -0,0 +1,79 @@
+package okex
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+const (
+	// ChannelTickers 行情频道
+	ChannelTickers = "tickers"
+	// ChannelBooks 400档增量深度，WSSClient在本地合并并校验checksum
+	ChannelBooks = "books"
+	// ChannelBooks5 5档全量深度
+	ChannelBooks5 = "books5"
+	// ChannelTrades 成交频道
+	ChannelTrades = "trades"
+	// ChannelCandle k线频道的前缀，如candle1m、candle1H
+	ChannelCandle = "candle"
+
+	pathPublic   = "/ws/v5/public"
+	pathBusiness = "/ws/v5/business"
+)
+
+// Subscription 一个频道订阅，应使用Tickers、Books等函数创建
+type Subscription struct {
+	Channel string `json:"channel"`
+	InstID  string `json:"instId,omitempty"`
+}
+
+// Tickers 订阅交易对的行情
+func Tickers(base, quote string) Subscription {
+	return Subscription{ChannelTickers, instID(base, quote)}
+}
+
+// Books 订阅交易对的400档增量深度
+func Books(base, quote string) Subscription {
+	return Subscription{ChannelBooks, instID(base, quote)}
+}
+
+// Books5 订阅交易对的5档深度
+func Books5(base, quote string) Subscription {
+	return Subscription{ChannelBooks5, instID(base, quote)}
+}
+
+// Trades 订阅交易对的成交
+func Trades(base, quote string) Subscription {
+	return Subscription{ChannelTrades, instID(base, quote)}
+}
+
+// Candles 订阅交易对的k线，period同GetKline
+func Candles(base, quote, period string) Subscription {
+	return Subscription{ChannelCandle + klineBar(period), instID(base, quote)}
+}
+
+// path 返回频道所在的地址，k线频道在business地址上
+func (s Subscription) path() string {
+	if strings.HasPrefix(s.Channel, ChannelCandle) {
+		return pathBusiness
+	}
+	return pathPublic
+}
+
+func subRequest(op string, args ...Subscription) interface{} {
+	return struct {
+		Op   string         `json:"op"`
+		Args []Subscription `json:"args"`
+	}{op, args}
+}
+
+// wsURL 返回websocket地址，模拟盘需要带上brokerId
+func wsURL(cfg *config.Config, path string) string {
+	u := url.URL{Scheme: "wss", Host: *cfg.WSSHost, Path: path}
+	if cfg.Env() == config.Testnet {
+		u.RawQuery = "brokerId=9999"
+	}
+	return u.String()
+}
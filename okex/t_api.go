@@ -0,0 +1,139 @@
+package okex
+
+import (
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+// GetFund 查询交易账户的余额
+func (c *Client) GetFund(req global.FundReq) ([]global.Fund, error) {
+	var r []struct {
+		Details []BalanceDetail `json:"details"`
+	}
+	if e := c.doHTTPRetry("GET", "/api/v5/account/balance", nil, &r, true); e != nil {
+		return nil, e
+	}
+	ret := []global.Fund{}
+	for _, a := range r {
+		for _, d := range a.Details {
+			ret = append(ret, global.Fund{
+				Base:      d.Ccy,
+				Available: toFloat(d.AvailBal),
+				Frozen:    toFloat(d.FrozenBal),
+			})
+		}
+	}
+	return ret, nil
+}
+
+// InsertOrder 币币下单
+// 市价买单的req.Num表示花费的计价币数量，其余情况表示买卖的币数量
+// 设置req.ClientOrderID后下单失败时会先确认订单是否已经生效，再按重试策略重试
+func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
+	in := map[string]string{
+		"instId":  instID(req.Base, req.Quote),
+		"tdMode":  "cash",
+		"side":    "buy",
+		"ordType": "limit",
+		"sz":      toString(req.Num),
+	}
+	if req.Direction == 1 {
+		in["side"] = "sell"
+	}
+	if req.Type == 1 {
+		in["ordType"] = "market"
+		if req.Direction == 0 {
+			in["tgtCcy"] = "quote_ccy"
+		}
+	} else {
+		in["px"] = toString(req.Price)
+	}
+	if req.ClientOrderID != "" {
+		in["clOrdId"] = req.ClientOrderID
+	}
+
+	var r []OrderResult
+	place := func() error {
+		return c.doHTTP("POST", "/api/v5/trade/order", nil, in, &r, true)
+	}
+	var orderNo string
+	// 只有带上客户端订单号时才能确认下单是否已经生效，才允许重试
+	var lookup func() (bool, error)
+	if req.ClientOrderID != "" {
+		lookup = func() (bool, error) {
+			o, e := c.orderDetail(in["instId"], "", req.ClientOrderID)
+			if oe, ok := e.(*Error); ok && oe.Code == "51603" {
+				// 订单不存在
+				return false, nil
+			}
+			if e != nil {
+				return false, e
+			}
+			orderNo = o.OrdID
+			return true, nil
+		}
+	}
+	if e := c.config.RetryDoOrder("okex", place, lookup); e != nil {
+		return global.InsertRsp{}, e
+	}
+	if orderNo == "" && len(r) != 0 {
+		orderNo = r[0].OrdID
+	}
+	return global.InsertRsp{OrderNo: orderNo}, nil
+}
+
+// CancelOrder 撤单
+// 注意，返回OK表示撤单请求成功。订单是否撤销成功请调用订单查询接口查询该订单状态
+func (c *Client) CancelOrder(req global.CancelReq) error {
+	in := map[string]string{
+		"instId": instID(req.Base, req.Quote),
+		"ordId":  req.OrderNo,
+	}
+	return c.doHTTP("POST", "/api/v5/trade/cancel-order", nil, in, nil, true)
+}
+
+// OrderStatus 查询订单状态，TradePrice为成交均价
+func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
+	var o OrderDetail
+	e := c.config.RetryDo("okex", func() (err error) {
+		o, err = c.orderDetail(instID(req.Base, req.Quote), req.OrderNo, "")
+		return err
+	})
+	if e != nil {
+		return global.StatusRsp{}, e
+	}
+	m := global.StatusRsp{
+		TradePrice: toFloat(o.AvgPx),
+		TradeNum:   toFloat(o.AccFillSz),
+	}
+	switch o.State {
+	case "live":
+		m.Status, m.StatusMsg = global.HANGING, "未成交"
+	case "partially_filled":
+		m.Status, m.StatusMsg = global.HALFTRADE, "部分成交"
+	case "filled":
+		m.Status, m.StatusMsg = global.COMPLETETRADE, "完全成交"
+	case "canceled", "mmp_canceled":
+		m.Status, m.StatusMsg = global.CANCELED, "已撤单"
+	default:
+		m.Status, m.StatusMsg = global.FAILED, o.State
+	}
+	return m, nil
+}
+
+// orderDetail 通过订单号或客户端订单号查询订单
+func (c *Client) orderDetail(instID, ordID, clOrdID string) (OrderDetail, error) {
+	in := map[string]string{"instId": instID}
+	if ordID != "" {
+		in["ordId"] = ordID
+	} else {
+		in["clOrdId"] = clOrdID
+	}
+	var r []OrderDetail
+	if e := c.doHTTP("GET", "/api/v5/trade/order", in, nil, &r, true); e != nil {
+		return OrderDetail{}, e
+	}
+	if len(r) == 0 {
+		return OrderDetail{}, &Error{Code: "51603", Message: "Order does not exist"}
+	}
+	return r[0], nil
+}
@@ -0,0 +1,47 @@
+package okex
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/global"
+)
+
+// instID 返回交易对的产品id，如BTC-USDT
+func instID(base, quote string) string {
+	return strings.ToUpper(base) + "-" + strings.ToUpper(quote)
+}
+
+// splitInstID 拆分产品id
+func splitInstID(id string) global.TradeSymbol {
+	i := strings.Index(id, "-")
+	if i < 0 {
+		return global.TradeSymbol{Base: id}
+	}
+	return global.TradeSymbol{Base: id[:i], Quote: id[i+1:]}
+}
+
+func toFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func toInt(s string) int64 {
+	i, _ := strconv.ParseInt(s, 10, 64)
+	return i
+}
+
+func toDepthPairs(levels [][]string) []global.DepthPair {
+	r := make([]global.DepthPair, 0, len(levels))
+	for _, l := range levels {
+		if len(l) < 2 {
+			continue
+		}
+		r = append(r, global.DepthPair{Price: toFloat(l[0]), Size: toFloat(l[1])})
+	}
+	return r
+}
+
+func toString(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
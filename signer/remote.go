@@ -0,0 +1,108 @@
+package signer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// Remote 通过本地socket把签名交给单独的签名进程，secret只保存在签名进程中
+// 每次签名建立一个连接，发送一行json请求并读取一行json响应，签名进程可以使用Serve实现
+type Remote struct {
+	Network string
+	Addr    string
+	KeyID   string
+	Timeout time.Duration
+}
+
+var _ config.Signer = (*Remote)(nil)
+
+type remoteRequest struct {
+	KeyID   string `json:"key_id"`
+	Payload []byte `json:"payload"`
+}
+
+type remoteResponse struct {
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// NewRemote 创建一个通过unix socket addr签名的Remote，keyID为签名进程中的密钥名称
+func NewRemote(addr, keyID string) *Remote {
+	return &Remote{Network: "unix", Addr: addr, KeyID: keyID, Timeout: 2 * time.Second}
+}
+
+// Sign 请求签名进程对payload签名
+func (r *Remote) Sign(payload []byte) (string, error) {
+	network := r.Network
+	if network == "" {
+		network = "unix"
+	}
+	conn, err := net.DialTimeout(network, r.Addr, r.Timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if r.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(r.Timeout))
+	}
+
+	if err := json.NewEncoder(conn).Encode(remoteRequest{r.KeyID, payload}); err != nil {
+		return "", err
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return "", err
+	}
+	var rsp remoteResponse
+	if err := json.Unmarshal(line, &rsp); err != nil {
+		return "", err
+	}
+	if rsp.Error != "" {
+		return "", fmt.Errorf("remote signer: %s", rsp.Error)
+	}
+	if rsp.Signature == "" {
+		return "", errors.New("remote signer: empty signature")
+	}
+	return rsp.Signature, nil
+}
+
+// Serve 在l上处理Remote发来的签名请求，signers以KeyID为key，l关闭或出错时返回
+func Serve(l net.Listener, signers map[string]config.Signer) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveConn(conn, signers)
+	}
+}
+
+func serveConn(conn net.Conn, signers map[string]config.Signer) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req remoteRequest
+		var rsp remoteResponse
+		if err := json.Unmarshal(line, &req); err != nil {
+			rsp.Error = "bad request"
+		} else if s, ok := signers[req.KeyID]; !ok {
+			rsp.Error = "unknown key " + req.KeyID
+		} else if rsp.Signature, err = s.Sign(req.Payload); err != nil {
+			rsp.Error = err.Error()
+		}
+		if enc.Encode(rsp) != nil {
+			return
+		}
+	}
+}
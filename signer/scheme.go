@@ -0,0 +1,97 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// HMAC 使用hmac签名，Encode决定签名结果的编码方式
+type HMAC struct {
+	Hash   func() hash.Hash
+	Key    []byte
+	Encode func([]byte) string
+}
+
+// Sign 返回payload编码后的hmac
+func (s *HMAC) Sign(payload []byte) (string, error) {
+	mac := hmac.New(s.Hash, s.Key)
+	mac.Write(payload)
+	return s.Encode(mac.Sum(nil)), nil
+}
+
+func upperHex(b []byte) string {
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+func hexDigest(h hash.Hash, s string) string {
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HMACSHA256Hex HMAC-SHA256，小写十六进制，binance使用
+func HMACSHA256Hex(secret string) config.Signer {
+	return &HMAC{sha256.New, []byte(secret), hex.EncodeToString}
+}
+
+// HMACSHA256Base64 HMAC-SHA256，base64编码，huobi、okex使用
+func HMACSHA256Base64(secret string) config.Signer {
+	return &HMAC{sha256.New, []byte(secret), base64.StdEncoding.EncodeToString}
+}
+
+// HMACSHA256UpperHex HMAC-SHA256，大写十六进制，bitstamp使用
+func HMACSHA256UpperHex(secret string) config.Signer {
+	return &HMAC{sha256.New, []byte(secret), upperHex}
+}
+
+// HMACSHA512Hex HMAC-SHA512，小写十六进制，gate使用
+func HMACSHA512Hex(secret string) config.Signer {
+	return &HMAC{sha512.New, []byte(secret), hex.EncodeToString}
+}
+
+// HMACMD5SHA1Key HMAC-MD5，密钥为secret的sha1十六进制，小写十六进制，zb使用
+func HMACMD5SHA1Key(secret string) config.Signer {
+	return &HMAC{md5.New, []byte(hexDigest(sha1.New(), secret)), hex.EncodeToString}
+}
+
+// HMACSHA256MD5Key HMAC-SHA256，密钥为secret的md5十六进制，小写十六进制，coinegg使用
+func HMACSHA256MD5Key(secret string) config.Signer {
+	return &HMAC{sha256.New, []byte(hexDigest(md5.New(), secret)), hex.EncodeToString}
+}
+
+// MD5Secret 在待签名内容后追加&secret_key=secret再取md5，大写十六进制，coinex、weex使用
+type MD5Secret struct {
+	Secret string
+}
+
+// Sign 返回签名
+func (s *MD5Secret) Sign(payload []byte) (string, error) {
+	h := md5.New()
+	h.Write(payload)
+	h.Write([]byte("&secret_key=" + s.Secret))
+	return upperHex(h.Sum(nil)), nil
+}
+
+// MD5WithSecret 创建一个MD5Secret
+func MD5WithSecret(secret string) config.Signer {
+	return &MD5Secret{secret}
+}
+
+// Schemes 按名称索引的签名方式，供签名进程和配置文件选择
+var Schemes = map[string]config.Scheme{
+	"hmac-sha256-hex":      HMACSHA256Hex,
+	"hmac-sha256-base64":   HMACSHA256Base64,
+	"hmac-sha256-upperhex": HMACSHA256UpperHex,
+	"hmac-sha512-hex":      HMACSHA512Hex,
+	"hmac-md5-sha1key":     HMACMD5SHA1Key,
+	"hmac-sha256-md5key":   HMACSHA256MD5Key,
+	"md5-secret":           MD5WithSecret,
+}
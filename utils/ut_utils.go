@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"log"
 	"reflect"
 	"strconv"
 	"time"
@@ -47,9 +46,6 @@ func ToFloat(i interface{}) float64 {
 	case reflect.Float32, reflect.Float64:
 		f = v.Float()
 		break
-	default:
-		log.Printf("toFloat type error %v\n", v.Kind())
-		break
 	}
 
 	return f
@@ -110,3 +106,15 @@ func Period2Suffix(period string, special1h bool) string {
 	}
 	return period
 }
+
+// CopyMap 浅拷贝请求参数，避免签名等字段在重试时被重复写入
+func CopyMap(in map[string]interface{}) map[string]interface{} {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
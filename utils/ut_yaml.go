@@ -0,0 +1,347 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// YAMLToJSON 将YAML文本转换为JSON，便于使用json标签解析到结构体
+// 只支持配置文件常用的子集: 以缩进表示的映射和列表、单行标量、引号字符串和#注释，
+// 不支持锚点、多行字符串、流式写法({}、[]仅支持空值)等
+// 没有引号的标量按内容推断为数字、布尔或字符串，解析到结构体时应使用YAMLToJSONFor
+func YAMLToJSON(data []byte) ([]byte, error) {
+	return YAMLToJSONFor(data, nil)
+}
+
+// YAMLToJSONFor 与YAMLToJSON相同，但没有引号的标量按v中对应字段的类型转换:
+// 字段为string时保持原样(如passphrase: 123456、secret: 007)，为数字或布尔时才转换，
+// v可以是结构体、map、slice或它们的指针，只用于获取类型
+func YAMLToJSONFor(data []byte, v interface{}) ([]byte, error) {
+	tree, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(yamlTyped(tree, reflect.TypeOf(v)))
+}
+
+// yamlPlain 没有引号的标量，转换为JSON前按目标类型决定是否转为数字或布尔
+type yamlPlain string
+
+func parseYAML(data []byte) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n") {
+		text := stripYAMLComment(raw)
+		if strings.TrimSpace(text) == "" || strings.TrimSpace(text) == "---" {
+			continue
+		}
+		if strings.Contains(text, "\t") {
+			return nil, fmt.Errorf("yaml 第%d行: 不支持使用tab缩进", i+1)
+		}
+		trimmed := strings.TrimLeft(text, " ")
+		lines = append(lines, yamlLine{no: i + 1, indent: len(text) - len(trimmed), text: strings.TrimRight(trimmed, " ")})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	p := &yamlParser{lines: lines}
+	v, err := p.parse(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("yaml 第%d行: 缩进不正确", p.lines[p.pos].no)
+	}
+	return v, nil
+}
+
+// yamlTyped 按目标类型t转换树中没有引号的标量，t为nil或interface{}时按内容推断
+func yamlTyped(v interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := v.(type) {
+	case yamlPlain:
+		return yamlScalar(string(v), t)
+	case map[string]interface{}:
+		for k, item := range v {
+			var et reflect.Type
+			if t != nil {
+				switch t.Kind() {
+				case reflect.Map:
+					et = t.Elem()
+				case reflect.Struct:
+					et = yamlFieldType(t, k)
+				}
+			}
+			v[k] = yamlTyped(item, et)
+		}
+	case []interface{}:
+		var et reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			et = t.Elem()
+		}
+		for i, item := range v {
+			v[i] = yamlTyped(item, et)
+		}
+	}
+	return v
+}
+
+// yamlScalar 转换没有引号的标量，无法转换为目标类型时保留字符串，由json解析时报错
+func yamlScalar(s string, t reflect.Type) interface{} {
+	kind := reflect.Interface
+	if t != nil {
+		kind = t.Kind()
+	}
+	switch kind {
+	case reflect.Bool:
+		if b, ok := yamlBool(s); ok {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case reflect.Interface:
+		if b, ok := yamlBool(s); ok {
+			return b
+		}
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+func yamlBool(s string) (bool, bool) {
+	switch s {
+	case "true", "True", "TRUE":
+		return true, true
+	case "false", "False", "FALSE":
+		return false, true
+	}
+	return false, false
+}
+
+// yamlFieldType 按json标签查找结构体字段的类型，与encoding/json一样不区分大小写
+func yamlFieldType(t reflect.Type, key string) reflect.Type {
+	var fold reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			// 没有json名称的嵌入结构体，字段提升到外层
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if r := yamlFieldType(ft, key); r != nil {
+					return r
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return f.Type
+		}
+		if fold == nil && strings.EqualFold(name, key) {
+			fold = f.Type
+		}
+	}
+	return fold
+}
+
+type yamlLine struct {
+	no     int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parse 解析从当前行开始、缩进为indent的一个块
+func (p *yamlParser) parse(indent int) (interface{}, error) {
+	if strings.HasPrefix(p.lines[p.pos].text, "-") && isYAMLItem(p.lines[p.pos].text) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseMap(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("yaml 第%d行: 缩进不正确", l.no)
+		}
+		key, rest, ok := splitYAMLKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("yaml 第%d行: 应为 key: value", l.no)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("yaml 第%d行: 重复的key %s", l.no, key)
+		}
+		p.pos++
+		v, err := p.value(rest, indent, l.no)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func (p *yamlParser) parseSeq(indent int) (interface{}, error) {
+	s := []interface{}{}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent || !isYAMLItem(l.text) {
+			return nil, fmt.Errorf("yaml 第%d行: 缩进不正确", l.no)
+		}
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if _, _, ok := splitYAMLKey(rest); ok {
+			// "- key: value" 开始一个映射，后续的key与第一个key对齐
+			p.lines[p.pos] = yamlLine{no: l.no, indent: indent + len(l.text) - len(rest), text: rest}
+			v, err := p.parseMap(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+			continue
+		}
+		p.pos++
+		v, err := p.value(rest, indent, l.no)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+	return s, nil
+}
+
+// value 解析key或列表项之后的值，为空时读取下一层缩进的块
+func (p *yamlParser) value(rest string, indent, no int) (interface{}, error) {
+	if rest != "" {
+		return parseYAMLScalar(rest, no)
+	}
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent || (next.indent == indent && isYAMLItem(next.text)) {
+			return p.parse(next.indent)
+		}
+	}
+	return nil, nil
+}
+
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey 拆分 key: value，key可以带引号
+func splitYAMLKey(text string) (string, string, bool) {
+	if text == "" || isYAMLItem(text) {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 || !strings.HasPrefix(text[end+2:], ":") {
+			return "", "", false
+		}
+		key, err := unquoteYAML(text[:end+2])
+		if err != nil {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(text[end+3:]), true
+	}
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false
+		}
+		i = len(text) - 1
+	}
+	return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+}
+
+func parseYAMLScalar(s string, no int) (interface{}, error) {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "{}":
+		return map[string]interface{}{}, nil
+	case "[]":
+		return []interface{}{}, nil
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		v, err := unquoteYAML(s)
+		if err != nil {
+			return nil, fmt.Errorf("yaml 第%d行: %s", no, err.Error())
+		}
+		return v, nil
+	}
+	if s[0] == '{' || s[0] == '[' || s[0] == '&' || s[0] == '*' || s[0] == '|' || s[0] == '>' {
+		return nil, fmt.Errorf("yaml 第%d行: 不支持的写法 %s", no, s)
+	}
+	return yamlPlain(s), nil
+}
+
+func unquoteYAML(s string) (string, error) {
+	if len(s) < 2 || s[len(s)-1] != s[0] {
+		return "", fmt.Errorf("引号不匹配 %s", s)
+	}
+	if s[0] == '\'' {
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// stripYAMLComment 去掉不在引号内的#注释
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type yamlTestCred struct {
+	APIKey     string `json:"apikey"`
+	Secret     string `json:"secret"`
+	Passphrase string `json:"passphrase"`
+}
+
+type yamlTestConf struct {
+	Name    string                  `json:"name"`
+	Retries int                     `json:"retries"`
+	Ratio   float64                 `json:"ratio"`
+	Enabled *bool                   `json:"enabled"`
+	Tags    []string                `json:"tags"`
+	Creds   map[string]yamlTestCred `json:"creds"`
+	yamlTestEmbedded
+}
+
+type yamlTestEmbedded struct {
+	Port int `json:"port"`
+}
+
+func TestYAMLToJSONForKeepsStrings(t *testing.T) {
+	data := []byte(`
+name: 0123
+retries: 3
+ratio: 0.5
+enabled: true
+port: 8080
+tags:
+  - 1
+  - true
+  - 1e3
+creds:
+  okex:
+    apikey: 123456
+    secret: 007
+    passphrase: 123456
+  huobi:
+    apikey: true
+    secret: "42"
+    passphrase: 1.50
+`)
+	out, err := YAMLToJSONFor(data, &yamlTestConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c yamlTestConf
+	if err := json.Unmarshal(out, &c); err != nil {
+		t.Fatalf("unmarshal %s: %s", out, err)
+	}
+	if c.Name != "0123" || c.Retries != 3 || c.Ratio != 0.5 || c.Enabled == nil || !*c.Enabled || c.Port != 8080 {
+		t.Errorf("unexpected %+v", c)
+	}
+	tests := []struct {
+		got, want string
+	}{
+		{c.Tags[0], "1"},
+		{c.Tags[1], "true"},
+		{c.Tags[2], "1e3"},
+		{c.Creds["okex"].APIKey, "123456"},
+		{c.Creds["okex"].Secret, "007"},
+		{c.Creds["okex"].Passphrase, "123456"},
+		{c.Creds["huobi"].APIKey, "true"},
+		{c.Creds["huobi"].Secret, "42"},
+		{c.Creds["huobi"].Passphrase, "1.50"},
+	}
+	for i, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%d: got %q, want %q", i, tt.got, tt.want)
+		}
+	}
+}
+
+func TestYAMLToJSONInfersTypes(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"a: 1", `{"a":1}`},
+		{"a: 1.5", `{"a":1.5}`},
+		{"a: true", `{"a":true}`},
+		{"a: ~", `{"a":null}`},
+		{"a: 007x", `{"a":"007x"}`},
+		{`a: "1"`, `{"a":"1"}`},
+		{"a:\n  - x\n  - 2", `{"a":["x",2]}`},
+		{"", `null`},
+	}
+	for _, tt := range tests {
+		out, err := YAMLToJSON([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%q: %s", tt.in, err)
+			continue
+		}
+		if string(out) != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.in, out, tt.want)
+		}
+	}
+}
+
+func TestYAMLToJSONForMismatch(t *testing.T) {
+	// 无法转换为目标类型时保留字符串，由json报错
+	out, err := YAMLToJSONFor([]byte("retries: many"), yamlTestConf{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c yamlTestConf
+	if err := json.Unmarshal(out, &c); err == nil {
+		t.Errorf("expected error for %s", out)
+	}
+}
@@ -2,10 +2,10 @@ package weex
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 var deftSymbol = []global.TradeSymbol{
@@ -42,14 +42,14 @@ func (c *Client) recursionOrderStatus(page int, req global.StatusReq) (global.St
 		Data    []map[string]interface{} `json:"data"`
 	}{}
 	r := weexRsp{Data: &data}
-	err := c.httpReq("GET", "https://api.weex.com/v1/order/pending", in, &r, true)
+	err := c.httpReqRetry("GET", c.config.RESTURL("/v1/order/pending"), in, &r, true)
 	if err != nil {
 		return global.StatusRsp{}, err
 	}
 	if r.Code != 0 {
 		return global.StatusRsp{}, errors.New(r.Msg)
 	}
-	fmt.Printf("weex orderstatus %+v\n", r)
+	c.logger.Logf(core.Debug, "weex orderstatus %+v", r)
 
 	// 遍历data 找到订单号和请求订单号相同的订单
 	for _, d := range data.Data {
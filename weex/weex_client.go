@@ -3,20 +3,24 @@ package weex
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
+	"github.com/blockcdn-go/exchange-sdk-go/utils"
 	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
 	jsoniter "github.com/json-iterator/go"
 )
 
 // Client 提供weex API的调用客户端
 type Client struct {
 	config    config.Config
+	logger    core.Logger
 	sock      *websocket.Conn
 	once      sync.Once
 	mutex     sync.Mutex
@@ -30,56 +34,72 @@ type Client struct {
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(nil, config)
 	}
+	cfg.ApplyDryRun("weex", dryRun)
 
 	return &Client{
 		config:    *cfg,
+		logger:    cfg.ExchangeLogger("weex"),
 		tick:      make(map[global.TradeSymbol]chan global.Ticker),
 		depth:     make(map[global.TradeSymbol]chan global.Depth),
 		latetrade: make(map[global.TradeSymbol]chan global.LateTrade),
 	}
 }
 
-func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.config.ObserveRequest("weex", endpoint, status, err, start) }()
 	if in == nil {
 		in = make(map[string]interface{})
 	}
+	var s config.Signer
 	if bs {
-		in["access_id"] = *c.config.APIKey
+		var cred config.Credentials
+		if cred, s, err = c.config.Signing("weex", signer.MD5WithSecret); err != nil {
+			return err
+		}
+		in["access_id"] = cred.APIKey
 	}
 	presign := urlEncode(in)
 	if len(in) != 0 {
 		path += "?" + presign
 	}
-	if bs {
-		presign += "&secret_key=" + *c.config.Secret
-	}
 	rbody, _ := json.Marshal(in)
 	if method == "GET" {
 		rbody = []byte{}
 	}
 	req, err := http.NewRequest(method, path, bytes.NewReader(rbody))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) "+
 		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
 	if bs {
-		sign := sign(*c.config.Secret, presign)
+		sign, err := s.Sign([]byte(presign))
+		if err != nil {
+			return err
+		}
 		req.Header.Set("authorization", sign)
 	}
-	resp, err := c.config.HTTPClient.Do(req)
+	ctx, cancel := c.config.RequestContext()
+	defer cancel()
+	resp, err := c.config.Do("weex", req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	//fmt.Printf("http message: %s\n", string(body))
+	config.LogResponse(c.logger, req, start, body)
 	//extra.RegisterFuzzyDecoders()
 
 	err = jsoniter.Unmarshal(body, out)
@@ -88,3 +108,10 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	return nil
 }
+
+// httpReqRetry 按配置的重试策略执行幂等的httpReq请求
+func (c *Client) httpReqRetry(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+	return c.config.RetryDo("weex", func() error {
+		return c.httpReq(method, path, utils.CopyMap(in), out, bs)
+	})
+}
@@ -5,11 +5,13 @@ import (
 
 	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/gorilla/websocket"
 )
 
 func defaultConfig() *config.Config {
 	cfg := &config.Config{}
 
+	// 交易对列表固定使用www.weexpro.com，不受RESTHost影响
 	cfg.WithRESTHost("api.weex.com")
 	cfg.WithSecret("")
 	cfg.WithAPIKey("")
@@ -17,6 +19,7 @@ func defaultConfig() *config.Config {
 	// u, _ := url.Parse("http://127.0.0.1:8118")
 	// transport.Proxy = http.ProxyURL(u)
 	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithWSSDialer(websocket.DefaultDialer)
 	cfg.WithUseSSL(true)
 
 	return cfg
@@ -0,0 +1,20 @@
+package weex
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单和撤单的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	switch {
+	case req.Method == "POST" && strings.HasPrefix(req.URL.Path, "/v1/order/"):
+		return []byte(fmt.Sprintf(`{"code":0,"message":"dry run","data":{"id":%d}}`, config.DryRunOrderID())), true
+	case req.Method == "DELETE" && req.URL.Path == "/v1/order/pending":
+		return []byte(`{"code":0,"message":"dry run","data":{}}`), true
+	}
+	return nil, false
+}
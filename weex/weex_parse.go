@@ -2,11 +2,11 @@ package weex
 
 import (
 	"encoding/json"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 func (c *Client) parse(msg []byte) {
@@ -29,7 +29,7 @@ func (c *Client) parse(msg []byte) {
 			ch, ok := c.depth[key]
 			c.mutex.Unlock()
 			if !ok {
-				log.Printf("收到一个没有找到对应的消息 %+v %s\n", key, string(msg))
+				c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
 				continue
 			}
 
@@ -63,7 +63,12 @@ func (c *Client) parse(msg []byte) {
 					Size:  toFloat(vb[1]),
 				})
 			}
-			ch <- ret
+			c.config.ObserveMessage("weex", "depth:"+key.Base+key.Quote)
+			select {
+			case ch <- ret:
+			default:
+				c.config.ObserveDrop("weex", "depth:"+key.Base+key.Quote)
+			}
 		}
 	} else if strings.Contains(r.Method, "today") {
 		len := (len(r.Params) / 2) * 2
@@ -74,7 +79,7 @@ func (c *Client) parse(msg []byte) {
 			ch, ok := c.tick[key]
 			c.mutex.Unlock()
 			if !ok {
-				log.Printf("收到一个没有找到对应的消息 %+v %s\n", key, string(msg))
+				c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
 				continue
 			}
 			v1m := r.Params[i+1].(map[string]interface{})
@@ -92,7 +97,12 @@ func (c *Client) parse(msg []byte) {
 				ret.PriceChange = v
 				ret.PriceChangePercent = v / open * 100
 			}
-			ch <- ret
+			c.config.ObserveMessage("weex", "ticker:"+key.Base+key.Quote)
+			select {
+			case ch <- ret:
+			default:
+				c.config.ObserveDrop("weex", "ticker:"+key.Base+key.Quote)
+			}
 		}
 	} else if strings.Contains(r.Method, "deals") {
 		len := (len(r.Params) / 2) * 2
@@ -103,7 +113,7 @@ func (c *Client) parse(msg []byte) {
 			ch, ok := c.latetrade[key]
 			c.mutex.Unlock()
 			if !ok {
-				log.Printf("收到一个没有找到对应的消息 %+v %s\n", key, string(msg))
+				c.logger.Logf(core.Warn, "收到一个没有找到对应的消息 %+v %s", key, string(msg))
 				continue
 			}
 			sl := r.Params[i+1].([]interface{})
@@ -120,7 +130,12 @@ func (c *Client) parse(msg []byte) {
 					Dircetion: toString(v1m["type"]),
 				}
 				lt.Total = lt.Price * lt.Num
-				ch <- lt
+				c.config.ObserveMessage("weex", "trade:"+key.Base+key.Quote)
+				select {
+				case ch <- lt:
+				default:
+					c.config.ObserveDrop("weex", "trade:"+key.Base+key.Quote)
+				}
 			}
 		}
 	}
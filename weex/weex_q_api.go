@@ -43,7 +43,7 @@ func (c *Client) GetDepth(req global.TradeSymbol) (global.Depth, error) {
 		Bids [][]string `json:"bids"`
 	}{}
 	r := weexRsp{Data: &d}
-	err := c.httpReq("GET", "https://api.weex.com/v1/market/depth", in, &r, false)
+	err := c.httpReqRetry("GET", c.config.RESTURL("/v1/market/depth"), in, &r, false)
 	if err != nil {
 		return global.Depth{}, err
 	}
@@ -95,7 +95,7 @@ func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
 	in["type"] = period
 	d := [][]interface{}{}
 	r := weexRsp{Data: &d}
-	err := c.httpReq("GET", "https://api.weex.com/v1/market/kline", in, &r, false)
+	err := c.httpReqRetry("GET", c.config.RESTURL("/v1/market/kline"), in, &r, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1,13 +1,9 @@
 package weex
 
 import (
-	"crypto/md5"
-	"fmt"
-	"log"
 	"net/url"
 	"reflect"
 	"strconv"
-	"strings"
 )
 
 type sortPair struct {
@@ -78,14 +74,6 @@ func sliceEncode(s []sortPair) string {
 	return str
 }
 
-func sign(apikey, str string) string {
-
-	h := md5.New()
-	h.Write([]byte(str))
-	s := fmt.Sprintf("%x", h.Sum(nil))
-	return strings.ToUpper(s)
-}
-
 func toString(i interface{}) string {
 	v := reflect.ValueOf(i)
 	if v.Kind() == reflect.Ptr {
@@ -124,9 +112,6 @@ func toFloat(i interface{}) float64 {
 	case reflect.Float32, reflect.Float64:
 		f = v.Float()
 		break
-	default:
-		log.Printf("toFloat type error %v\n", v.Kind())
-		break
 	}
 
 	return f
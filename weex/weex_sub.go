@@ -2,13 +2,13 @@ package weex
 
 import (
 	"errors"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
 	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
 )
 
 // SubTicker ...
@@ -33,9 +33,9 @@ func (c *Client) SubTicker(sreq global.TradeSymbol) (chan global.Ticker, error)
 		req.Params = append(req.Params, strings.ToUpper(k.Base+k.Quote))
 	}
 
-	err := c.sock.WriteJSON(req)
+	err := c.config.WriteJSONFrame("weex", c.sock, req)
 	if err != nil {
-		log.Printf("发送消息失败 %+v %s\n", req, err.Error())
+		c.logger.Logf(core.Warn, "发送消息失败 %+v %s", req, err.Error())
 		return nil, err
 	}
 
@@ -65,9 +65,9 @@ func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 	}{ID: time.Now().Unix(), Method: "depth.subscribe", Params: []interface{}{}}
 	req.Params = append(req.Params, sreq.Base+sreq.Quote, 20, "0")
 
-	err = con.WriteJSON(req)
+	err = c.config.WriteJSONFrame("weex", con, req)
 	if err != nil {
-		log.Printf("发送消息失败 %+v %s\n", req, err.Error())
+		c.logger.Logf(core.Warn, "发送消息失败 %+v %s", req, err.Error())
 		return nil, err
 	}
 
@@ -80,7 +80,8 @@ func (c *Client) depthLoop(sreq global.TradeSymbol, con *websocket.Conn) {
 		msg, err := c.readWSMessage(con)
 		if err != nil {
 			for {
-				log.Printf("weex disconnect %+v, reconnect after five seconds\n", err)
+				c.logger.Logf(core.Warn, "weex disconnect %+v, reconnect after five seconds", err)
+				c.config.ObserveReconnect("weex", "depth")
 				time.Sleep(5 * time.Second)
 				_, err := c.SubDepth(sreq)
 				if err != nil {
@@ -114,9 +115,9 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 		req.Params = append(req.Params, strings.ToUpper(k.Base+k.Quote))
 	}
 
-	err := c.sock.WriteJSON(req)
+	err := c.config.WriteJSONFrame("weex", c.sock, req)
 	if err != nil {
-		log.Printf("发送消息失败 %+v %s\n", req, err.Error())
+		c.logger.Logf(core.Warn, "发送消息失败 %+v %s", req, err.Error())
 		return nil, err
 	}
 
@@ -125,9 +126,9 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 
 func (c *Client) connect() (*websocket.Conn, error) {
 	wsaddr := "wss://ws.weexpro.com/"
-	log.Printf("weex 连接 %s 中... ", wsaddr)
-	conn, _, err := c.config.WSSDialer.Dial(wsaddr, nil)
-	log.Printf("连接: %s\n", utils.Ternary(err == nil, "成功", "失败").(string))
+	c.logger.Logf(core.Info, "weex 连接 %s 中... ", wsaddr)
+	conn, _, err := c.config.DialWSS(wsaddr, nil)
+	c.logger.Logf(core.Info, "连接: %s", utils.Ternary(err == nil, "成功", "失败").(string))
 	return conn, err
 }
 
@@ -135,7 +136,7 @@ func (c *Client) wsConnect() error {
 	c.sock = nil
 	conn, err := c.connect()
 	if err != nil {
-		log.Printf("weex connect failed %+v\n", err)
+		c.logger.Logf(core.Warn, "weex connect failed %+v", err)
 		c.sock = nil
 		return err
 	}
@@ -143,7 +144,7 @@ func (c *Client) wsConnect() error {
 
 	//在这儿进行订阅消息重放
 	if c.replay {
-		log.Printf("连接成功，进行消息重放\n")
+		c.logger.Logf(core.Info, "连接成功，进行消息重放")
 		req := struct {
 			ID     int64    `json:"id"`
 			Method string   `json:"method"`
@@ -153,9 +154,9 @@ func (c *Client) wsConnect() error {
 		for k := range c.tick {
 			req.Params = append(req.Params, strings.ToUpper(k.Base+k.Quote))
 		}
-		err := c.sock.WriteJSON(req)
+		err := c.config.WriteJSONFrame("weex", c.sock, req)
 		if err != nil {
-			log.Printf("订阅消息重放失败 %+v %s\n", req, err.Error())
+			c.logger.Logf(core.Warn, "订阅消息重放失败 %+v %s", req, err.Error())
 		}
 
 		req.Params = []string{}
@@ -163,9 +164,9 @@ func (c *Client) wsConnect() error {
 		for k := range c.latetrade {
 			req.Params = append(req.Params, strings.ToUpper(k.Base+k.Quote))
 		}
-		err = c.sock.WriteJSON(req)
+		err = c.config.WriteJSONFrame("weex", c.sock, req)
 		if err != nil {
-			log.Printf("订阅消息重放失败 %+v %s\n", req, err.Error())
+			c.logger.Logf(core.Warn, "订阅消息重放失败 %+v %s", req, err.Error())
 		}
 
 		c.mutex.Unlock()
@@ -177,7 +178,8 @@ func (c *Client) wsConnect() error {
 		for {
 			msg, err := c.readWSMessage(c.sock)
 			if err != nil {
-				log.Printf("weex < %s > 断开连接，五秒后重连...\n", err.Error())
+				c.logger.Logf(core.Warn, "weex < %s > 断开连接，五秒后重连...", err.Error())
+				c.config.ObserveReconnect("weex", "market")
 				go func() {
 					time.Sleep(5 * time.Second)
 					c.wsConnect()
@@ -198,7 +200,7 @@ func (c *Client) wsConnect() error {
 }
 
 func (c *Client) readWSMessage(conn *websocket.Conn) ([]byte, error) {
-	_, msg, err := conn.ReadMessage()
+	_, msg, err := c.config.ReadFrame("weex", conn)
 	if err != nil {
 		return nil, err
 	}
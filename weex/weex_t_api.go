@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
+	"github.com/gotoxu/log/core"
 )
 
 // GetFund 获取帐号资金余额
@@ -15,7 +16,7 @@ func (c *Client) GetFund(global.FundReq) ([]global.Fund, error) {
 		Frozen    string `json:"frozen"`
 	}{}
 	r := weexRsp{Data: &d}
-	err := c.httpReq("GET", "https://api.weex.com/v1/balance/", nil, &r, true)
+	err := c.httpReqRetry("GET", c.config.RESTURL("/v1/balance/"), nil, &r, true)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +46,7 @@ func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
 	if req.Direction == 1 {
 		d = "sell"
 	}
-	path := fmt.Sprintf("https://api.weex.com/v1/order/%s", t)
+	path := fmt.Sprintf(c.config.RESTURL("/v1/order/%s"), t)
 
 	in["access_id"] = req.APIKey
 	in["market"] = strings.ToUpper(req.Base + req.Quote)
@@ -62,7 +63,7 @@ func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
 	if r.Code != 0 {
 		return global.InsertRsp{}, errors.New(r.Msg)
 	}
-	fmt.Printf("weex insert %+v\n", r)
+	c.logger.Logf(core.Debug, "weex insert %+v", r)
 	return global.InsertRsp{OrderNo: toString(data["id"])}, nil
 }
 
@@ -76,14 +77,14 @@ func (c *Client) CancelOrder(req global.CancelReq) error {
 
 	data := map[string]interface{}{}
 	r := weexRsp{Data: &data}
-	err := c.httpReq("DELETE", "https://api.weex.com/v1/order/pending", in, &r, true)
+	err := c.httpReq("DELETE", c.config.RESTURL("/v1/order/pending"), in, &r, true)
 	if err != nil {
 		return err
 	}
 	if r.Code != 0 {
 		return errors.New(r.Msg)
 	}
-	fmt.Printf("weex cancel %+v\n", r)
+	c.logger.Logf(core.Debug, "weex cancel %+v", r)
 	return nil
 }
 
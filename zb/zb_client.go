@@ -3,13 +3,14 @@ package zb
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/blockcdn-go/exchange-sdk-go/signer"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/gotoxu/log/core"
 
 	"github.com/blockcdn-go/exchange-sdk-go/config"
 	"github.com/blockcdn-go/exchange-sdk-go/global"
@@ -21,6 +22,9 @@ import (
 // Client 提供zb API的调用客户端
 type Client struct {
 	config    config.Config
+	logger    core.Logger
+	clock     *config.Clock
+	nonce     *config.Nonce
 	tickOnce  sync.Once
 	tickSock  *websocket.Conn
 	otherOnce sync.Once
@@ -36,25 +40,38 @@ type Client struct {
 func NewClient(config *config.Config) *Client {
 	cfg := defaultConfig()
 	if config != nil {
-		cfg.MergeIn(config)
+		cfg.MergeInEnv(nil, config)
 	}
+	cfg.ApplyDryRun("zb", dryRun)
 	extra.RegisterFuzzyDecoders()
+	clock := cfg.StartClock(cfg.HTTPDate(cfg.RESTURL("/")))
 	return &Client{
 		config:    *cfg,
+		logger:    cfg.ExchangeLogger("zb"),
+		clock:     clock,
+		nonce:     cfg.NewNonce(time.Millisecond, clock),
 		tick:      make(map[global.TradeSymbol]chan global.Ticker),
 		depth:     make(map[global.TradeSymbol]chan global.Depth),
 		latetrade: make(map[global.TradeSymbol]chan global.LateTrade),
 	}
 }
 
-func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+func (c *Client) httpReq(method, path string, in map[string]interface{}, out interface{}, bs bool) (err error) {
+	start, status, endpoint := time.Now(), 0, path
+	defer func() { c.config.ObserveRequest("zb", endpoint, status, err, start) }()
 	if in == nil {
 		in = make(map[string]interface{})
 	}
 	sig := ""
 	if bs {
-		in["accesskey"] = *c.config.APIKey
-		sig = sign(utils.MapEncode(in), *c.config.Secret)
+		cred, s, err := c.config.Signing("zb", signer.HMACMD5SHA1Key)
+		if err != nil {
+			return err
+		}
+		in["accesskey"] = cred.APIKey
+		if sig, err = s.Sign([]byte(utils.MapEncode(in))); err != nil {
+			return err
+		}
 	}
 	rbody, _ := json.Marshal(in)
 	if method == "GET" {
@@ -62,29 +79,39 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	path += "?" + utils.MapEncode(in)
 	if bs {
+		reqTime, err := c.nonce.Next()
+		if err != nil {
+			return err
+		}
 		path += "&sign=" + sig
-		path += "&reqTime=" + utils.ToString(time.Now().UnixNano()/1000000)
+		path += "&reqTime=" + utils.ToString(reqTime)
 	}
 
-	fmt.Println(path)
 	req, err := http.NewRequest(method, path, bytes.NewReader(rbody))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) "+
 		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
 
-	resp, err := c.config.HTTPClient.Do(req)
+	ctx, cancel := c.config.RequestContext()
+	defer cancel()
+	resp, err := c.config.Do("zb", req.WithContext(ctx))
 	if err != nil {
 		return err
 	}
+	status = resp.StatusCode
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("请求失败，响应码：%d", resp.StatusCode)
+		return &global.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("http message: %s\n", string(body))
+	config.LogResponse(c.logger, req, start, body)
 	//extra.RegisterFuzzyDecoders()
 
 	err = jsoniter.Unmarshal(body, out)
@@ -93,3 +120,10 @@ func (c *Client) httpReq(method, path string, in map[string]interface{}, out int
 	}
 	return nil
 }
+
+// httpReqRetry 按配置的重试策略执行幂等的httpReq请求
+func (c *Client) httpReqRetry(method, path string, in map[string]interface{}, out interface{}, bs bool) error {
+	return c.config.RetryDo("zb", func() error {
+		return c.httpReq(method, path, utils.CopyMap(in), out, bs)
+	})
+}
@@ -5,18 +5,21 @@ import (
 
 	"github.com/blockcdn-go/exchange-sdk-go/clean"
 	"github.com/blockcdn-go/exchange-sdk-go/config"
+	"github.com/gorilla/websocket"
 )
 
 func defaultConfig() *config.Config {
 	cfg := &config.Config{}
 
-	cfg.WithRESTHost("api.weex.com")
+	// RESTHost只用于交易接口，行情接口固定使用api.zb.com
+	cfg.WithRESTHost("trade.zb.com")
 	cfg.WithSecret("")
 	cfg.WithAPIKey("")
 	transport := clean.DefaultPooledTransport()
 	// u, _ := url.Parse("http://127.0.0.1:8118")
 	// transport.Proxy = http.ProxyURL(u)
 	cfg.WithHTTPClient(&http.Client{Transport: transport})
+	cfg.WithWSSDialer(websocket.DefaultDialer)
 	cfg.WithUseSSL(true)
 
 	return cfg
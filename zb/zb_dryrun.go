@@ -0,0 +1,19 @@
+package zb
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/blockcdn-go/exchange-sdk-go/config"
+)
+
+// dryRun 模拟下单和撤单的响应
+func dryRun(req *http.Request) ([]byte, bool) {
+	switch req.URL.Path {
+	case "/api/order":
+		return []byte(fmt.Sprintf(`{"message":"dry run","id":"%d"}`, config.DryRunOrderID())), true
+	case "/api/cancelOrder":
+		return []byte(`{"message":"dry run"}`), true
+	}
+	return nil, false
+}
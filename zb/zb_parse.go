@@ -1,11 +1,11 @@
 package zb
 
 import (
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/gotoxu/log/core"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 
@@ -22,7 +22,7 @@ func (c *Client) parse(msg []byte) {
 	}
 	err := jsoniter.Unmarshal(msg, &data)
 	if err != nil {
-		fmt.Printf("json error: %+v\n", err)
+		c.logger.Logf(core.Warn, "json error: %+v", err)
 		return
 	}
 	dtype, _ := data["dataType"].(string)
@@ -49,7 +49,12 @@ func (c *Client) parse(msg []byte) {
 				PriceChangePercent: utils.ToFloat(t["riseRate"]),
 			}
 			ret.PriceChange = ret.LastPrice * (ret.PriceChangePercent / 100)
-			ch <- ret
+			c.config.ObserveMessage("zb", "ticker:"+key.Base+key.Quote)
+			select {
+			case ch <- ret:
+			default:
+				c.config.ObserveDrop("zb", "ticker:"+key.Base+key.Quote)
+			}
 		}
 	} else if strings.Contains(dtype, "depth") {
 		base, quote := split2(strings.ToUpper(data["channel"].(string)))
@@ -97,7 +102,12 @@ func (c *Client) parse(msg []byte) {
 			}
 		}
 
-		ch <- ret
+		c.config.ObserveMessage("zb", "depth:"+key.Base+key.Quote)
+		select {
+		case ch <- ret:
+		default:
+			c.config.ObserveDrop("zb", "depth:"+key.Base+key.Quote)
+		}
 
 	} else if strings.Contains(dtype, "trades") {
 		ds, e := data["data"]
@@ -127,10 +137,15 @@ func (c *Client) parse(msg []byte) {
 			}
 			lt.Total = lt.Price * lt.Num
 
-			ch <- lt
+			c.config.ObserveMessage("zb", "trade:"+key.Base+key.Quote)
+			select {
+			case ch <- lt:
+			default:
+				c.config.ObserveDrop("zb", "trade:"+key.Base+key.Quote)
+			}
 		}
 
 	} else {
-		fmt.Println(string(msg))
+		c.logger.Logln(core.Debug, string(msg))
 	}
 }
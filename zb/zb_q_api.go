@@ -35,7 +35,7 @@ func (c *Client) GetDepth(req global.TradeSymbol) (global.Depth, error) {
 		Asks [][]float64 `json:"asks"`
 		Bids [][]float64 `json:"bids"`
 	}{}
-	err := c.httpReq("GET", "http://api.zb.com/data/v1/depth", arg, &r, false)
+	err := c.httpReqRetry("GET", "http://api.zb.com/data/v1/depth", arg, &r, false)
 	if err != nil {
 		return global.Depth{}, err
 	}
@@ -83,7 +83,7 @@ func (c *Client) GetKline(req global.KlineReq) ([]global.Kline, error) {
 		errInfo
 		Data [][]float64 `json:"data"`
 	}{}
-	err := c.httpReq("GET", "http://api.zb.com/data/v1/kline", arg, &r, false)
+	err := c.httpReqRetry("GET", "http://api.zb.com/data/v1/kline", arg, &r, false)
 	if err != nil {
 		return nil, err
 	}
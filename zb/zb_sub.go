@@ -3,13 +3,13 @@ package zb
 import (
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
 	"github.com/gorilla/websocket"
+	"github.com/gotoxu/log/core"
 )
 
 // SubLateTrade 查询交易详细数据
@@ -28,7 +28,7 @@ func (c *Client) SubLateTrade(sreq global.TradeSymbol) (chan global.LateTrade, e
 		C string `json:"channel"`
 	}{E: "addChannel"}
 	req.C = fmt.Sprintf("%s_trades", strings.ToLower(sreq.Base+sreq.Quote))
-	c.otherSock.WriteJSON(req)
+	c.config.WriteJSONFrame("zb", c.otherSock, req)
 	return ch, nil
 }
 
@@ -63,14 +63,14 @@ func (c *Client) SubDepth(sreq global.TradeSymbol) (chan global.Depth, error) {
 		C string `json:"channel"`
 	}{E: "addChannel"}
 	req.C = fmt.Sprintf("%s_depth", strings.ToLower(sreq.Base+sreq.Quote))
-	c.otherSock.WriteJSON(req)
+	c.config.WriteJSONFrame("zb", c.otherSock, req)
 	return ch, nil
 }
 
 func (c *Client) connect(wsaddr string) (*websocket.Conn, error) {
-	log.Printf("ZB 连接 %s 中... ", wsaddr)
-	conn, _, err := c.config.WSSDialer.Dial(wsaddr, nil)
-	log.Printf("连接: %s\n", utils.Ternary(err == nil, "成功", "失败").(string))
+	c.logger.Logf(core.Info, "ZB 连接 %s 中... ", wsaddr)
+	conn, _, err := c.config.DialWSS(wsaddr, nil)
+	c.logger.Logf(core.Info, "连接: %s", utils.Ternary(err == nil, "成功", "失败").(string))
 	return conn, err
 }
 
@@ -78,7 +78,7 @@ func (c *Client) wsTickerConnect() error {
 	c.tickSock = nil
 	conn, err := c.connect("wss://kline.zb.com:2443/websocket")
 	if err != nil {
-		log.Printf("zb <wss://kline.zb.com:2443/websocket> connect failed %+v\n", err)
+		c.logger.Logf(core.Warn, "zb <wss://kline.zb.com:2443/websocket> connect failed %+v", err)
 		c.tickSock = nil
 		return err
 	}
@@ -92,20 +92,21 @@ func (c *Client) wsTickerConnect() error {
 		Z string `json:"isZip"`
 	}{E: "addChannel", B: "false", Z: "false"}
 	req.C = "top_all_qc"
-	c.tickSock.WriteJSON(req)
+	c.config.WriteJSONFrame("zb", c.tickSock, req)
 	req.C = "top_all_zb"
-	c.tickSock.WriteJSON(req)
+	c.config.WriteJSONFrame("zb", c.tickSock, req)
 	req.C = "top_all_usdt"
-	c.tickSock.WriteJSON(req)
+	c.config.WriteJSONFrame("zb", c.tickSock, req)
 	req.C = "top_all_btc"
-	c.tickSock.WriteJSON(req)
+	c.config.WriteJSONFrame("zb", c.tickSock, req)
 
 	// 循环读取消息
 	go func() {
 		for {
 			msg, err := c.readWSMessage(c.tickSock)
 			if err != nil {
-				log.Printf("ZB < %s > 断开连接，五秒后重连...\n", err.Error())
+				c.logger.Logf(core.Warn, "ZB < %s > 断开连接，五秒后重连...", err.Error())
+				c.config.ObserveReconnect("zb", "ticker")
 				go func() {
 					time.Sleep(5 * time.Second)
 					c.wsTickerConnect()
@@ -129,7 +130,7 @@ func (c *Client) wsOtherConnect() error {
 	c.otherSock = nil
 	conn, err := c.connect("wss://api.zb.com:9999/websocket")
 	if err != nil {
-		log.Printf("zb <wss://api.zb.com:9999/websocket> connect failed %+v\n", err)
+		c.logger.Logf(core.Warn, "zb <wss://api.zb.com:9999/websocket> connect failed %+v", err)
 		c.otherSock = nil
 		return err
 	}
@@ -143,11 +144,11 @@ func (c *Client) wsOtherConnect() error {
 		c.mutex.Lock()
 		for k := range c.depth {
 			req.C = fmt.Sprintf("%s_depth", strings.ToLower(k.Base+k.Quote))
-			c.otherSock.WriteJSON(req)
+			c.config.WriteJSONFrame("zb", c.otherSock, req)
 		}
 		for k := range c.latetrade {
 			req.C = fmt.Sprintf("%s_trades", strings.ToLower(k.Base+k.Quote))
-			c.otherSock.WriteJSON(req)
+			c.config.WriteJSONFrame("zb", c.otherSock, req)
 		}
 		c.mutex.Unlock()
 	}
@@ -157,7 +158,8 @@ func (c *Client) wsOtherConnect() error {
 		for {
 			msg, err := c.readWSMessage(c.otherSock)
 			if err != nil {
-				log.Printf("ZB < %s > 断开连接，五秒后重连...\n", err.Error())
+				c.logger.Logf(core.Warn, "ZB < %s > 断开连接，五秒后重连...", err.Error())
+				c.config.ObserveReconnect("zb", "market")
 				go func() {
 					time.Sleep(5 * time.Second)
 					c.wsOtherConnect()
@@ -179,7 +181,7 @@ func (c *Client) wsOtherConnect() error {
 }
 
 func (c *Client) readWSMessage(conn *websocket.Conn) ([]byte, error) {
-	_, msg, err := conn.ReadMessage()
+	_, msg, err := c.config.ReadFrame("zb", conn)
 	if err != nil {
 		return nil, err
 	}
@@ -2,11 +2,11 @@ package zb
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/blockcdn-go/exchange-sdk-go/global"
 	"github.com/blockcdn-go/exchange-sdk-go/utils"
+	"github.com/gotoxu/log/core"
 )
 
 // GetFund 获取帐号资金余额
@@ -22,7 +22,7 @@ func (c *Client) GetFund(global.FundReq) ([]global.Fund, error) {
 		} `json:"result"`
 	}{}
 	r.Result.Coins = &f
-	err := c.httpReq("GET", "https://trade.zb.com/api/getAccountInfo", arg, &r, true)
+	err := c.httpReqRetry("GET", c.config.RESTURL("/api/getAccountInfo"), arg, &r, true)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +54,7 @@ func (c *Client) InsertOrder(req global.InsertReq) (global.InsertRsp, error) {
 		errInfo
 		ID string `json:"id"`
 	}{}
-	err := c.httpReq("GET", "https://trade.zb.com/api/order", arg, &r, true)
+	err := c.httpReq("GET", c.config.RESTURL("/api/order"), arg, &r, true)
 	if err != nil {
 		return global.InsertRsp{}, err
 	}
@@ -73,7 +73,7 @@ func (c *Client) CancelOrder(req global.CancelReq) error {
 	arg["id"] = req.OrderNo
 	arg["currency"] = strings.ToLower(req.Base + "_" + req.Quote)
 	r := errInfo{}
-	err := c.httpReq("GET", "https://trade.zb.com/api/cancelOrder", arg, &r, true)
+	err := c.httpReq("GET", c.config.RESTURL("/api/cancelOrder"), arg, &r, true)
 	if err != nil {
 		return err
 	}
@@ -92,7 +92,7 @@ func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 	arg["currency"] = strings.ToLower(req.Base + "_" + req.Quote)
 
 	r := map[string]interface{}{}
-	err := c.httpReq("GET", "https://trade.zb.com/api/getOrder", arg, &r, true)
+	err := c.httpReqRetry("GET", c.config.RESTURL("/api/getOrder"), arg, &r, true)
 	if err != nil {
 		return ret, err
 	}
@@ -111,6 +111,6 @@ func (c *Client) OrderStatus(req global.StatusReq) (global.StatusRsp, error) {
 		ret.Status = global.COMPLETETRADE
 		ret.StatusMsg = "完全成交"
 	}
-	fmt.Printf("zb order status %+v\n", r)
+	c.logger.Logf(core.Debug, "zb order status %+v", r)
 	return ret, nil
 }